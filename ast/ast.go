@@ -3,7 +3,7 @@ package ast
 import (
 	"bytes"
 	"interp/token"
-	"strconv"
+	"math/big"
 	"strings"
 )
 
@@ -43,22 +43,34 @@ func (p *Program) String() string {
 	return out.String()
 }
 
-type MarkerStatement struct {
-	Token  token.Token // the token.LEX_COLON token
-	Marker *Identifier
+// LabeledStatement is a statement preceded by a label that goto can jump
+// to. A label right before `end` marks an EmptyStatement.
+type LabeledStatement struct {
+	Token     token.Token // the token.LEX_COLON token
+	Label     *Identifier
+	Statement Statement
 }
 
-func (ms *MarkerStatement) statementNode()       {}
-func (ms *MarkerStatement) TokenLiteral() string { return ms.Token.Literal }
-func (ms *MarkerStatement) String() string {
+func (ls *LabeledStatement) statementNode()       {}
+func (ls *LabeledStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *LabeledStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(ms.Marker.String())
-	out.WriteString(ms.TokenLiteral())
+	out.WriteString(ls.Label.String())
+	out.WriteString(ls.TokenLiteral())
 	out.WriteString("\n")
+	out.WriteString(ls.Statement.String())
 	return out.String()
 }
 
+type EmptyStatement struct {
+	Token token.Token
+}
+
+func (es *EmptyStatement) statementNode()       {}
+func (es *EmptyStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *EmptyStatement) String() string       { return "" }
+
 type DeclStatment struct {
 	Token token.Token // the token.LEX_COLON token
 	Name  *Identifier
@@ -92,7 +104,7 @@ type DeclStatmentVector struct {
 	Token token.Token // token.KW_VECTOR
 	Name  *Identifier
 	Type  *Type
-	Size  uint64
+	Dims  []Expression // size of each dimension: *IntegerLiteral or a constant *Identifier
 }
 
 func (ds *DeclStatmentVector) statementNode() {}
@@ -104,29 +116,141 @@ func (ds *DeclStatmentVector) String() string {
 
 	out.WriteString(ds.Name.String())
 	out.WriteString(": ")
+	out.WriteString(ds.typeString())
+	out.WriteString(";\n")
+
+	return out.String()
+}
+
+func (ds *DeclStatmentVector) typeString() string {
+	var out bytes.Buffer
+
+	dims := []string{}
+	for _, d := range ds.Dims {
+		dims = append(dims, d.String())
+	}
+
 	out.WriteString(ds.TokenLiteral())
 	out.WriteString("[")
-	out.WriteString(strconv.FormatUint(uint64(ds.Size), 10))
+	out.WriteString(strings.Join(dims, ", "))
 	out.WriteString("]")
 	out.WriteString(" of ")
 	out.WriteString(ds.Type.String())
-	out.WriteString(";\n")
 
 	return out.String()
 }
 
-type AssignStatement struct {
-	Token token.Token
+type ConstStatement struct {
+	Token token.Token // the 'const' token
 	Name  *Identifier
 	Value Expression
 }
 
+func (cs *ConstStatement) statementNode()       {}
+func (cs *ConstStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ConstStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("const ")
+	out.WriteString(cs.Name.String())
+	out.WriteString(" = ")
+	out.WriteString(cs.Value.String())
+	out.WriteString(";\n")
+
+	return out.String()
+}
+
+// TypeStatement names a record type: `type point = record x, y: real end;`.
+type TypeStatement struct {
+	Token  token.Token // the 'type' token
+	Name   *Identifier
+	Record *RecordType
+}
+
+func (ts *TypeStatement) statementNode()       {}
+func (ts *TypeStatement) TokenLiteral() string { return ts.Token.Literal }
+func (ts *TypeStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("type ")
+	out.WriteString(ts.Name.String())
+	out.WriteString(" = ")
+	out.WriteString(ts.Record.String())
+	out.WriteString(";\n")
+
+	return out.String()
+}
+
+type RecordType struct {
+	Token  token.Token // the 'record' token
+	Fields []Statement // *DeclStatment or *DeclStatmentVector, one per field
+}
+
+func (rt *RecordType) TokenLiteral() string { return rt.Token.Literal }
+func (rt *RecordType) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("record ")
+	for _, f := range rt.Fields {
+		out.WriteString(strings.TrimSuffix(f.String(), "\n"))
+		out.WriteString(" ")
+	}
+	out.WriteString("end")
+
+	return out.String()
+}
+
+// DeclGroupStatement is `a, b: type;`, kept together so that String
+// reproduces the source; each name has its own declaration in Decls.
+type DeclGroupStatement struct {
+	Token token.Token // the token.LEX_COLON token
+	Decls []Statement // *DeclStatment or *DeclStatmentVector
+}
+
+func (dg *DeclGroupStatement) statementNode()       {}
+func (dg *DeclGroupStatement) TokenLiteral() string { return dg.Token.Literal }
+func (dg *DeclGroupStatement) String() string {
+	var out bytes.Buffer
+
+	names := []string{}
+	typ := ""
+	for _, d := range dg.Decls {
+		switch d := d.(type) {
+		case *DeclStatment:
+			names = append(names, d.Name.String())
+			typ = d.Type.String()
+		case *DeclStatmentVector:
+			names = append(names, d.Name.String())
+			typ = d.typeString()
+		}
+	}
+
+	out.WriteString(strings.Join(names, ", "))
+	out.WriteString(dg.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(typ)
+	out.WriteString(";\n")
+
+	return out.String()
+}
+
+type AssignStatement struct {
+	Token  token.Token
+	Name   *Identifier // the variable assigned to, or holding the target element
+	Target Expression  // the element or field assigned to, nil for a whole variable
+	Value  Expression
+}
+
 func (as *AssignStatement) statementNode()       {}
 func (as *AssignStatement) TokenLiteral() string { return as.Token.Literal }
 func (as *AssignStatement) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(as.Name.String())
+	if as.Target != nil {
+		out.WriteString(as.Target.String())
+	} else {
+		out.WriteString(as.Name.String())
+	}
 	out.WriteString(" := ")
 	out.WriteString(as.Value.String())
 	out.WriteString(";\n")
@@ -154,12 +278,48 @@ func (t *Type) String() string       { return t.Value }
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int // set instead of Value when the literal does not fit in int64
 }
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
 func (il *IntegerLiteral) String() string       { return il.Token.Literal }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
+
+type StringLiteral struct {
+	Token token.Token
+	Value string
+}
+
+var stringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+
+func (sl *StringLiteral) expressionNode()      {}
+func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) String() string {
+	return `"` + stringEscaper.Replace(sl.Value) + `"`
+}
+
+type CharLiteral struct {
+	Token token.Token
+	Value rune
+}
+
+var charEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\t", `\t`)
+
+func (cl *CharLiteral) expressionNode()      {}
+func (cl *CharLiteral) TokenLiteral() string { return cl.Token.Literal }
+func (cl *CharLiteral) String() string {
+	return `'` + charEscaper.Replace(string(cl.Value)) + `'`
+}
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -173,6 +333,9 @@ func (pe *PrefixExpression) String() string {
 
 	out.WriteString("(")
 	out.WriteString(pe.Operator)
+	if pe.Token.Type == token.KW_NOT {
+		out.WriteString(" ")
+	}
 	out.WriteString(pe.Right.String())
 	out.WriteString(")")
 
@@ -256,6 +419,47 @@ func (ie *IfExpression) String() string {
 	return out.String()
 }
 
+// CaseExpression is `case x of 1, 2: stmt; 3: stmt; else stmts end`. The
+// labels of an arm are integer constant expressions.
+type CaseExpression struct {
+	Token       token.Token // The 'case' token
+	Subject     Expression
+	Arms        []*CaseArm
+	Alternative *BlockStatement
+}
+
+type CaseArm struct {
+	Labels []Expression
+	Body   *BlockStatement // the single statement of the arm
+}
+
+func (ce *CaseExpression) expressionNode()      {}
+func (ce *CaseExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CaseExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("case ")
+	out.WriteString(ce.Subject.String())
+	out.WriteString(" of\n")
+
+	for _, arm := range ce.Arms {
+		labels := []string{}
+		for _, l := range arm.Labels {
+			labels = append(labels, l.String())
+		}
+		out.WriteString(strings.Join(labels, ", "))
+		out.WriteString(": ")
+		out.WriteString(arm.Body.String())
+	}
+
+	if ce.Alternative != nil {
+		out.WriteString("else\n")
+		out.WriteString(ce.Alternative.String())
+	}
+	out.WriteString(" end\n")
+	return out.String()
+}
+
 type LoopExpression struct {
 	Token token.Token
 	Body  Expression
@@ -273,6 +477,76 @@ func (ls *LoopExpression) String() string {
 	return out.String()
 }
 
+type WhileExpression struct {
+	Token     token.Token // the 'while' token
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (we *WhileExpression) expressionNode()      {}
+func (we *WhileExpression) TokenLiteral() string { return we.Token.Literal }
+func (we *WhileExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while ")
+	out.WriteString(we.Condition.String())
+	out.WriteString(" do\n")
+	out.WriteString(we.Body.String())
+	out.WriteString("end\n")
+
+	return out.String()
+}
+
+type RepeatExpression struct {
+	Token     token.Token // the 'repeat' token
+	Body      *BlockStatement
+	Condition Expression
+}
+
+func (re *RepeatExpression) expressionNode()      {}
+func (re *RepeatExpression) TokenLiteral() string { return re.Token.Literal }
+func (re *RepeatExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("repeat\n")
+	out.WriteString(re.Body.String())
+	out.WriteString("until ")
+	out.WriteString(re.Condition.String())
+
+	return out.String()
+}
+
+type ForExpression struct {
+	Token    token.Token // the 'for' token
+	Variable *Identifier
+	From     Expression
+	To       Expression
+	Downto   bool
+	Body     *BlockStatement
+}
+
+func (fe *ForExpression) expressionNode()      {}
+func (fe *ForExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *ForExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for ")
+	out.WriteString(fe.Variable.String())
+	out.WriteString(" := ")
+	out.WriteString(fe.From.String())
+	if fe.Downto {
+		out.WriteString(" downto ")
+	} else {
+		out.WriteString(" to ")
+	}
+	out.WriteString(fe.To.String())
+	out.WriteString(" do\n")
+	out.WriteString(fe.Body.String())
+	out.WriteString("end\n")
+
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      token.Token // the { token
 	Statements []Statement
@@ -307,6 +581,226 @@ func (gs *GotoStatement) String() string {
 	return out.String()
 }
 
+type ExitStatement struct {
+	Token     token.Token // the 'exit' token
+	Condition Expression  // nil for an unconditional exit
+}
+
+func (es *ExitStatement) statementNode()       {}
+func (es *ExitStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExitStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(es.Token.Literal)
+	if es.Condition != nil {
+		out.WriteString(" when ")
+		out.WriteString(es.Condition.String())
+	}
+	out.WriteString("\n")
+	return out.String()
+}
+
+// AssertStatement stops the program with an error naming the condition
+// when the condition does not hold.
+type AssertStatement struct {
+	Token     token.Token // the 'assert' token
+	Condition Expression
+}
+
+func (as *AssertStatement) statementNode()       {}
+func (as *AssertStatement) TokenLiteral() string { return as.Token.Literal }
+func (as *AssertStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(as.Token.Literal)
+	out.WriteString(" ")
+	out.WriteString(as.Condition.String())
+	out.WriteString("\n")
+	return out.String()
+}
+
+type Parameter struct {
+	Name  *Identifier
+	Type  *Type
+	ByRef bool // declared with var: the argument is the caller's variable
+}
+
+func (pr *Parameter) String() string {
+	var out bytes.Buffer
+
+	if pr.ByRef {
+		out.WriteString("var ")
+	}
+	out.WriteString(pr.Name.String())
+	out.WriteString(": ")
+	out.WriteString(pr.Type.String())
+
+	return out.String()
+}
+
+type ProcedureStatement struct {
+	Token      token.Token // the 'procedure' token
+	Name       *Identifier
+	Parameters []*Parameter
+	Body       *BeginExpression
+}
+
+func (ps *ProcedureStatement) statementNode()       {}
+func (ps *ProcedureStatement) TokenLiteral() string { return ps.Token.Literal }
+func (ps *ProcedureStatement) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range ps.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(ps.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(ps.Name.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, "; "))
+	out.WriteString(");\n")
+	out.WriteString(ps.Body.String())
+
+	return out.String()
+}
+
+type FunctionStatement struct {
+	Token      token.Token // the 'function' token
+	Name       *Identifier
+	Parameters []*Parameter
+	ReturnType *Type
+	Body       *BeginExpression
+}
+
+func (fs *FunctionStatement) statementNode()       {}
+func (fs *FunctionStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *FunctionStatement) String() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range fs.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(fs.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(fs.Name.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, "; "))
+	out.WriteString("): ")
+	out.WriteString(fs.ReturnType.String())
+	out.WriteString(";\n")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+type ReturnStatement struct {
+	Token       token.Token // the 'return' token
+	ReturnValue Expression  // nil when returning from a procedure
+}
+
+func (rs *ReturnStatement) statementNode()       {}
+func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(rs.TokenLiteral())
+	if rs.ReturnValue != nil {
+		out.WriteString(" ")
+		out.WriteString(rs.ReturnValue.String())
+	}
+	out.WriteString(";\n")
+
+	return out.String()
+}
+
+type IndexExpression struct {
+	Token   token.Token // the '[' token
+	Left    Expression
+	Indices []Expression
+}
+
+func (ie *IndexExpression) expressionNode()      {}
+func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) String() string {
+	var out bytes.Buffer
+
+	indices := []string{}
+	for _, i := range ie.Indices {
+		indices = append(indices, i.String())
+	}
+
+	out.WriteString(ie.Left.String())
+	out.WriteString("[")
+	out.WriteString(strings.Join(indices, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+type FieldExpression struct {
+	Token token.Token // the '.' token
+	Left  Expression
+	Field *Identifier
+}
+
+func (fe *FieldExpression) expressionNode()      {}
+func (fe *FieldExpression) TokenLiteral() string { return fe.Token.Literal }
+func (fe *FieldExpression) String() string {
+	return fe.Left.String() + "." + fe.Field.String()
+}
+
+type CallExpression struct {
+	Token     token.Token // the '(' token
+	Function  Expression  // Identifier of the called function
+	Arguments []Expression
+}
+
+func (ce *CallExpression) expressionNode()      {}
+func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range ce.Arguments {
+		args = append(args, a.String())
+	}
+
+	out.WriteString(ce.Function.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+type CallStatement struct {
+	Token     token.Token // the procedure name token
+	Name      *Identifier
+	Arguments []Expression
+}
+
+func (cs *CallStatement) statementNode()       {}
+func (cs *CallStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *CallStatement) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range cs.Arguments {
+		args = append(args, a.String())
+	}
+
+	out.WriteString(cs.Name.String())
+	out.WriteString("(")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(");\n")
+
+	return out.String()
+}
+
 type ReadExpression struct {
 	Token     token.Token
 	Arguments []Expression
@@ -329,3 +823,70 @@ func (re *ReadExpression) String() string {
 
 	return out.String()
 }
+
+type WriteExpression struct {
+	Token     token.Token
+	Arguments []Expression // expressions, WriteItems and WriteSpecifiers
+}
+
+func (we *WriteExpression) expressionNode()      {}
+func (we *WriteExpression) TokenLiteral() string { return we.Token.Literal }
+
+func (we *WriteExpression) String() string {
+	var out bytes.Buffer
+
+	args := []string{}
+	for _, a := range we.Arguments {
+		args = append(args, a.String())
+	}
+
+	out.WriteString("write ")
+	out.WriteString(strings.Join(args, ", "))
+	out.WriteString(";")
+
+	return out.String()
+}
+
+// WriteItem is a write argument with format suffixes: x:8 pads x to eight
+// columns, x:8:2 also prints the real x with two decimals, and n:H or n:8:H
+// prints the integer n as a hexadecimal literal (B binary, C octal).
+type WriteItem struct {
+	Token     token.Token // the first ':'
+	Value     Expression
+	Width     Expression // nil if not given
+	Precision Expression // nil if not given
+	Base      string     // "B", "C" or "H"; "" for decimal
+}
+
+func (wi *WriteItem) expressionNode()      {}
+func (wi *WriteItem) TokenLiteral() string { return wi.Token.Literal }
+
+func (wi *WriteItem) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(wi.Value.String())
+	if wi.Width != nil {
+		out.WriteString(":")
+		out.WriteString(wi.Width.String())
+	}
+	if wi.Precision != nil {
+		out.WriteString(":")
+		out.WriteString(wi.Precision.String())
+	}
+	if wi.Base != "" {
+		out.WriteString(":")
+		out.WriteString(wi.Base)
+	}
+
+	return out.String()
+}
+
+// WriteSpecifier is one of the layout items skip, space and tab of a write
+// list.
+type WriteSpecifier struct {
+	Token token.Token
+}
+
+func (ws *WriteSpecifier) expressionNode()      {}
+func (ws *WriteSpecifier) TokenLiteral() string { return ws.Token.Literal }
+func (ws *WriteSpecifier) String() string       { return ws.Token.Literal }
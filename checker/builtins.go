@@ -0,0 +1,96 @@
+package checker
+
+import "interp/ast"
+
+// signature describes a builtin function to the checker. The type "number"
+// accepts integer and real arguments; a "number" result is real if any
+// argument is real and integer otherwise.
+type signature struct {
+	params []string
+	result string
+}
+
+var builtins = map[string]*signature{
+	"abs":   {[]string{"number"}, "number"},
+	"sqr":   {[]string{"number"}, "number"},
+	"sqrt":  {[]string{"number"}, "real"},
+	"sin":   {[]string{"number"}, "real"},
+	"cos":   {[]string{"number"}, "real"},
+	"exp":   {[]string{"number"}, "real"},
+	"ln":    {[]string{"number"}, "real"},
+	"trunc": {[]string{"number"}, "integer"},
+	"round": {[]string{"number"}, "integer"},
+	"odd":   {[]string{"integer"}, "integer"},
+	"min":   {[]string{"number", "number"}, "number"},
+	"max":   {[]string{"number", "number"}, "number"},
+	"ord":   {[]string{"char"}, "integer"},
+	"chr":   {[]string{"integer"}, "char"},
+	"real":  {[]string{"number"}, "real"},
+
+	"assign":  {[]string{"file", "string"}, ""},
+	"reset":   {[]string{"file"}, ""},
+	"rewrite": {[]string{"file"}, ""},
+	"close":   {[]string{"file"}, ""},
+	"eof":     {[]string{"file"}, "integer"},
+
+	"halt": {[]string{"integer"}, ""},
+
+	"random":    {[]string{"integer"}, "integer"},
+	"randomize": {[]string{}, ""},
+}
+
+// DeclareBuiltin makes a function provided by the host known to the checker.
+// Parameters and result are type names; an empty result means the function
+// returns no value.
+func (c *Checker) DeclareBuiltin(name string, params []string, result string) {
+	c.scopes[0][name] = &symbol{kind: BUILTIN, sig: &signature{params: params, result: result}}
+}
+
+// SetIntegerDivide makes the checker expect "/" on two integers to truncate,
+// as the evaluator does when the runtime's IntegerDivide is set.
+func (c *Checker) SetIntegerDivide(on bool) {
+	c.integerDivide = on
+}
+
+// resultType returns the type of a call with arguments of the given types,
+// or "" when it cannot be known.
+func (sig *signature) resultType(args []string) string {
+	if sig.result != "number" {
+		return sig.result
+	}
+
+	result := "integer"
+	for _, typ := range args {
+		switch typ {
+		case "real":
+			return "real"
+		case "integer":
+		default:
+			result = ""
+		}
+	}
+	return result
+}
+
+func (c *Checker) checkBuiltinArguments(name string, sig *signature, args []ast.Expression) {
+	if len(args) != len(sig.params) {
+		c.errorf("wrong number of arguments to %s: want=%d, got=%d",
+			name, len(sig.params), len(args))
+		return
+	}
+
+	for i, param := range sig.params {
+		typ := c.typeOf(args[i])
+
+		if param == "number" {
+			if typ != "" && typ != "integer" && typ != "real" {
+				c.errorf("argument %d of %s must be integer or real, got %s", i+1, name, typ)
+			}
+			continue
+		}
+
+		if !assignable(param, typ) {
+			c.errorf("argument %d of %s must be %s, got %s", i+1, name, param, typ)
+		}
+	}
+}
@@ -0,0 +1,677 @@
+package checker
+
+import (
+	"fmt"
+	"interp/ast"
+	"math/big"
+)
+
+// Checker reports the errors that can be found in a parsed program before
+// it runs.
+type Checker struct {
+	errors    []string
+	loopDepth int
+	scopes    []map[string]*symbol // global scope first, innermost last
+	labels    []map[string]bool    // labels of the enclosing blocks, innermost last
+	routine   *ast.Identifier      // procedure or function being checked, if any
+	result    string               // result type of that routine, "" for a procedure
+
+	integerDivide bool // "/" on two integers gives an integer, see SetIntegerDivide
+}
+
+func New() *Checker {
+	global := make(map[string]*symbol)
+	for name, sig := range builtins {
+		global[name] = &symbol{kind: BUILTIN, sig: sig}
+	}
+
+	return &Checker{
+		errors: []string{},
+		scopes: []map[string]*symbol{global},
+	}
+}
+
+// Check walks the program and returns the errors found in it. A Checker may
+// be reused for consecutive programs that share one environment; a program
+// with errors is not run, so its global declarations are forgotten.
+func (c *Checker) Check(program *ast.Program) []string {
+	global := make(map[string]*symbol, len(c.scopes[0]))
+	for name, sym := range c.scopes[0] {
+		global[name] = sym
+	}
+
+	c.errors = []string{}
+	c.check(program)
+	if len(c.errors) != 0 {
+		c.scopes[0] = global
+	}
+	return c.errors
+}
+
+func (c *Checker) check(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.Program:
+		c.checkStatements(node.Statements)
+
+	case *ast.BlockStatement:
+		c.checkStatements(node.Statements)
+
+	case *ast.LabeledStatement:
+		c.check(node.Statement)
+
+	case *ast.GotoStatement:
+		if !c.labelVisible(node.Name.Value) {
+			c.errorf("undefined label %s", node.Name.Value)
+		}
+
+	case *ast.ExpressionStatement:
+		if ident, ok := node.Expression.(*ast.Identifier); ok {
+			if sym, ok := c.lookup(ident.Value); ok && sym.kind == PROCEDURE {
+				c.checkArguments(ident.Value, sym.params, nil)
+				return
+			}
+		}
+		c.check(node.Expression)
+
+	case *ast.Identifier:
+		c.checkName(node)
+
+	case *ast.DeclStatment:
+		c.declare(node.Name.Value, c.variable(node.Type.Value))
+
+	case *ast.DeclStatmentVector:
+		c.declare(node.Name.Value, c.vector(node))
+
+	case *ast.TypeStatement:
+		c.checkRecordType(node)
+
+	case *ast.ConstStatement:
+		c.check(node.Value)
+		if !c.isConstant(node.Value) {
+			c.errorf("value of constant %s is not a constant expression", node.Name.Value)
+		}
+		value, _ := c.constantValue(node.Value)
+		c.declare(node.Name.Value, &symbol{kind: CONSTANT, typ: c.typeOf(node.Value), value: value})
+
+	case *ast.DeclGroupStatement:
+		for _, decl := range node.Decls {
+			c.check(decl)
+		}
+
+	case *ast.AssignStatement:
+		if node.Target != nil {
+			c.check(node.Target)
+		}
+		c.check(node.Value)
+		sym, ok := c.lookup(node.Name.Value)
+		if ok && sym.kind == CONSTANT {
+			c.errorf("cannot assign to constant %s", node.Name.Value)
+		}
+		if node.Target != nil {
+			c.checkAssignment(node.Target.String(), c.symbolOf(node.Target), node.Value)
+		} else if ok {
+			c.checkAssignment(node.Name.Value, sym, node.Value)
+		}
+
+	case *ast.IndexExpression:
+		c.checkIndex(node)
+
+	case *ast.FieldExpression:
+		c.checkField(node)
+
+	case *ast.PrefixExpression:
+		c.check(node.Right)
+		if typ := c.typeOf(node.Right); node.Operator == "-" && typ != "" && !isNumber(typ) {
+			c.errorf("unknown operator: -%s", typ)
+		}
+
+	case *ast.InfixExpression:
+		c.check(node.Left)
+		c.check(node.Right)
+		c.checkOperands(node)
+
+	case *ast.BeginExpression:
+		c.check(node.Block)
+
+	case *ast.IfExpression:
+		c.check(node.Condition)
+		c.check(node.Consequence)
+		if node.Alternative != nil {
+			c.check(node.Alternative)
+		}
+
+	case *ast.CaseExpression:
+		c.checkCase(node)
+
+	case *ast.LoopExpression:
+		c.loopDepth++
+		c.check(node.Body)
+		c.loopDepth--
+
+	case *ast.WhileExpression:
+		c.check(node.Condition)
+		c.loopDepth++
+		c.check(node.Body)
+		c.loopDepth--
+
+	case *ast.RepeatExpression:
+		c.loopDepth++
+		c.check(node.Body)
+		c.loopDepth--
+		c.check(node.Condition)
+
+	case *ast.ForExpression:
+		c.checkForVariable(node.Variable)
+		c.check(node.From)
+		c.check(node.To)
+		for _, bound := range []ast.Expression{node.From, node.To} {
+			if typ := c.typeOf(bound); typ != "" && typ != "integer" {
+				c.errorf("for loop bound %s must be integer, got %s", bound.String(), typ)
+			}
+		}
+		c.loopDepth++
+		c.check(node.Body)
+		c.loopDepth--
+
+	case *ast.ExitStatement:
+		if c.loopDepth == 0 {
+			c.errorf("exit statement outside of a loop")
+		}
+		if node.Condition != nil {
+			c.check(node.Condition)
+		}
+
+	case *ast.AssertStatement:
+		c.check(node.Condition)
+
+	case *ast.ReadExpression:
+		for i, arg := range node.Arguments {
+			c.check(arg)
+			typ := c.typeOf(arg)
+			if i == 0 && typ == "file" {
+				continue
+			}
+			if c.isRecordType(typ) || typ == "file" {
+				c.errorf("cannot read %s of type %s", arg.String(), typ)
+			}
+			if sym := c.symbolOf(arg); typ == "vector" && sym != nil && (c.isRecordType(sym.elem) || sym.elem == "file") {
+				c.errorf("cannot read %s with elements of type %s", arg.String(), sym.elem)
+			}
+			ident, ok := arg.(*ast.Identifier)
+			if !ok {
+				continue
+			}
+			if sym, ok := c.lookup(ident.Value); ok && sym.kind == CONSTANT {
+				c.errorf("cannot read into constant %s", ident.Value)
+			}
+		}
+
+	case *ast.WriteExpression:
+		for i, arg := range node.Arguments {
+			c.check(arg)
+			if i > 0 && c.typeOf(arg) == "file" {
+				c.errorf("cannot write %s of type file", arg.String())
+			}
+		}
+
+	case *ast.WriteItem:
+		c.checkWriteItem(node)
+
+	case *ast.ProcedureStatement:
+		sym := &symbol{kind: PROCEDURE, params: node.Parameters}
+		c.checkRoutine(node.Name, sym, node.Body)
+
+	case *ast.FunctionStatement:
+		sym := &symbol{kind: FUNCTION, typ: node.ReturnType.Value, params: node.Parameters}
+		c.checkRoutine(node.Name, sym, node.Body)
+
+	case *ast.ReturnStatement:
+		c.checkReturn(node)
+
+	case *ast.CallStatement:
+		for _, arg := range node.Arguments {
+			c.check(arg)
+		}
+
+		sym, ok := c.lookup(node.Name.Value)
+		if !ok {
+			c.errorf("undefined procedure %s", node.Name.Value)
+			return
+		}
+		switch sym.kind {
+		case VARIABLE:
+			c.errorf("%s is not a procedure", node.Name.Value)
+		case BUILTIN:
+			c.checkBuiltinArguments(node.Name.Value, sym.sig, node.Arguments)
+		default:
+			c.checkArguments(node.Name.Value, sym.params, node.Arguments)
+		}
+
+	case *ast.CallExpression:
+		for _, arg := range node.Arguments {
+			c.check(arg)
+		}
+
+		ident, ok := node.Function.(*ast.Identifier)
+		if !ok {
+			c.errorf("%s is not a function", node.Function.String())
+			return
+		}
+
+		sym, ok := c.lookup(ident.Value)
+		if !ok {
+			c.errorf("undefined function %s", ident.Value)
+			return
+		}
+		switch sym.kind {
+		case FUNCTION:
+			c.checkArguments(ident.Value, sym.params, node.Arguments)
+		case BUILTIN:
+			c.checkBuiltinArguments(ident.Value, sym.sig, node.Arguments)
+			if sym.sig.result == "" {
+				c.errorf("%s does not return a value", ident.Value)
+			}
+		default:
+			c.errorf("%s is not a function", ident.Value)
+		}
+	}
+}
+
+// checkName checks a routine named without an argument list: functions
+// and builtins are called with no arguments, a procedure gives no value.
+func (c *Checker) checkName(node *ast.Identifier) {
+	sym, ok := c.lookup(node.Value)
+	if !ok {
+		return
+	}
+
+	switch sym.kind {
+	case PROCEDURE:
+		c.errorf("procedure %s does not return a value", node.Value)
+	case FUNCTION:
+		c.checkArguments(node.Value, sym.params, nil)
+	case BUILTIN:
+		c.checkBuiltinArguments(node.Value, sym.sig, nil)
+	}
+}
+
+// checkOperands checks that an infix operator applies to the types of its
+// operands. Numbers take every operator; strings and characters can only be
+// compared with their own type or joined into a string with +; records,
+// files and vectors take none.
+func (c *Checker) checkOperands(node *ast.InfixExpression) {
+	left, right := c.typeOf(node.Left), c.typeOf(node.Right)
+
+	switch {
+	case left == "" || right == "":
+	case isNumber(left) && isNumber(right):
+		if node.Operator == "div" && (left == "real" || right == "real") {
+			c.errorf("unknown operator: %s div %s", left, right)
+		}
+	case node.Operator == "+" && isText(left) && isText(right):
+		if left == "char" && right == "char" {
+			c.errorf("unknown operator: char + char")
+		}
+	case left != right:
+		c.errorf("type mismatch: %s %s %s", left, node.Operator, right)
+	case !isText(left) || !comparisons[node.Operator]:
+		c.errorf("unknown operator: %s %s %s", left, node.Operator, right)
+	}
+}
+
+var comparisons = map[string]bool{
+	"=": true, "<>": true, "<": true, ">": true, "<=": true, ">=": true,
+}
+
+// checkRoutine checks a procedure or function body in a scope of its own.
+// Loops and labels around the declaration are not visible from inside it.
+func (c *Checker) checkRoutine(name *ast.Identifier, sym *symbol, body *ast.BeginExpression) {
+	c.declare(name.Value, sym)
+
+	loopDepth, labels := c.loopDepth, c.labels
+	routine, result := c.routine, c.result
+	c.loopDepth, c.labels = 0, nil
+	c.routine, c.result = name, ""
+	if sym.kind == FUNCTION {
+		c.result = sym.typ
+	}
+	c.scopes = append(c.scopes, make(map[string]*symbol))
+
+	for _, param := range sym.params {
+		if _, ok := c.scopes[len(c.scopes)-1][param.Name.Value]; ok {
+			c.errorf("duplicate parameter %s of %s", param.Name.Value, name.Value)
+			continue
+		}
+		local := c.variable(param.Type.Value)
+		if !param.ByRef && holdsFile(local) {
+			c.errorf("parameter %s of %s must be var: files cannot be copied", param.Name.Value, name.Value)
+		}
+		c.declare(param.Name.Value, local)
+	}
+
+	c.check(body)
+
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.loopDepth, c.labels = loopDepth, labels
+	c.routine, c.result = routine, result
+}
+
+func (c *Checker) checkReturn(node *ast.ReturnStatement) {
+	if node.ReturnValue != nil {
+		c.check(node.ReturnValue)
+	}
+
+	switch {
+	case c.routine == nil:
+		c.errorf("return outside of a procedure or function")
+	case c.result == "":
+		if node.ReturnValue != nil {
+			c.errorf("procedure %s cannot return a value", c.routine.Value)
+		}
+	case node.ReturnValue == nil:
+		c.errorf("function %s must return a value", c.routine.Value)
+	default:
+		if typ := c.typeOf(node.ReturnValue); !assignable(c.result, typ) {
+			c.errorf("function %s must return %s, got %s", c.routine.Value, c.result, typ)
+		}
+	}
+}
+
+func (c *Checker) checkArguments(name string, params []*ast.Parameter, args []ast.Expression) {
+	if len(args) != len(params) {
+		c.errorf("wrong number of arguments to %s: want=%d, got=%d",
+			name, len(params), len(args))
+		return
+	}
+
+	for i, param := range params {
+		arg := args[i]
+
+		if param.ByRef {
+			if ident, ok := arg.(*ast.Identifier); !ok || c.isConstant(ident) {
+				c.errorf("argument %d of %s must be a variable, got %s",
+					i+1, name, arg.String())
+				continue
+			}
+			if typ := c.typeOf(arg); typ != "" && typ != param.Type.Value {
+				c.errorf("argument %d of %s must be a %s variable, got %s",
+					i+1, name, param.Type.Value, typ)
+			}
+			continue
+		}
+
+		if typ := c.typeOf(arg); !assignable(param.Type.Value, typ) {
+			c.errorf("argument %d of %s must be %s, got %s",
+				i+1, name, param.Type.Value, typ)
+		}
+	}
+}
+
+// checkStatements checks the statements of one block. Goto may only jump
+// to labels of this block or of the blocks around it.
+func (c *Checker) checkStatements(statements []ast.Statement) {
+	labels := make(map[string]bool)
+
+	for _, st := range statements {
+		for {
+			labeled, ok := st.(*ast.LabeledStatement)
+			if !ok {
+				break
+			}
+			if labels[labeled.Label.Value] {
+				c.errorf("duplicate label %s", labeled.Label.Value)
+			}
+			labels[labeled.Label.Value] = true
+			st = labeled.Statement
+		}
+	}
+
+	c.labels = append(c.labels, labels)
+	for _, st := range statements {
+		c.check(st)
+	}
+	c.labels = c.labels[:len(c.labels)-1]
+}
+
+func (c *Checker) checkCase(node *ast.CaseExpression) {
+	c.check(node.Subject)
+	if typ := c.typeOf(node.Subject); typ != "" && typ != "integer" {
+		c.errorf("case expression must be integer, got %s", typ)
+	}
+
+	seen := make(map[string]bool)
+	for _, arm := range node.Arms {
+		for _, label := range arm.Labels {
+			value, ok := c.constantValue(label)
+			if !ok {
+				c.errorf("case label %s is not an integer constant", label.String())
+				continue
+			}
+			if seen[value.String()] {
+				c.errorf("duplicate case label %s", value)
+			}
+			seen[value.String()] = true
+		}
+		c.check(arm.Body)
+	}
+
+	if node.Alternative != nil {
+		c.check(node.Alternative)
+	}
+}
+
+// variable describes a variable of a basic or record type.
+func (c *Checker) variable(typ string) *symbol {
+	return &symbol{kind: VARIABLE, typ: typ, fields: c.recordFields(typ)}
+}
+
+func (c *Checker) vector(node *ast.DeclStatmentVector) *symbol {
+	shape := make([]*big.Int, len(node.Dims))
+	for i, dim := range node.Dims {
+		size, ok := c.constantValue(dim)
+		if !ok || size.Sign() <= 0 {
+			c.errorf("size of vector %s must be a positive integer constant, got %s",
+				node.Name.Value, dim.String())
+			continue
+		}
+		shape[i] = size
+	}
+
+	return &symbol{
+		kind:   VARIABLE,
+		typ:    "vector",
+		elem:   node.Type.Value,
+		shape:  shape,
+		fields: c.recordFields(node.Type.Value),
+	}
+}
+
+// recordFields returns the fields of a record type, or nil for a basic
+// type. Names that are not types are reported.
+func (c *Checker) recordFields(typ string) map[string]*symbol {
+	switch typ {
+	case "integer", "real", "string", "char", "file":
+		return nil
+	}
+
+	sym, ok := c.lookup(typ)
+	if !ok {
+		c.errorf("undefined type %s", typ)
+		return nil
+	}
+	if sym.kind != TYPE {
+		c.errorf("%s is not a type", typ)
+		return nil
+	}
+	return sym.fields
+}
+
+func (c *Checker) isRecordType(typ string) bool {
+	sym, ok := c.lookup(typ)
+	return ok && sym.kind == TYPE
+}
+
+func (c *Checker) checkRecordType(node *ast.TypeStatement) {
+	fields := make(map[string]*symbol)
+
+	for _, field := range node.Record.Fields {
+		var name string
+		var sym *symbol
+		switch field := field.(type) {
+		case *ast.DeclStatment:
+			name, sym = field.Name.Value, c.variable(field.Type.Value)
+		case *ast.DeclStatmentVector:
+			name, sym = field.Name.Value, c.vector(field)
+		}
+
+		if _, ok := fields[name]; ok {
+			c.errorf("duplicate field %s of %s", name, node.Name.Value)
+		}
+		fields[name] = sym
+	}
+
+	c.declare(node.Name.Value, &symbol{kind: TYPE, typ: node.Name.Value, fields: fields})
+}
+
+func (c *Checker) checkIndex(node *ast.IndexExpression) {
+	c.check(node.Left)
+	for _, index := range node.Indices {
+		c.check(index)
+		if typ := c.typeOf(index); typ != "" && typ != "integer" {
+			c.errorf("vector index must be integer, got %s", typ)
+		}
+	}
+
+	sym := c.symbolOf(node.Left)
+	if sym == nil {
+		return
+	}
+
+	if sym.kind != VARIABLE || sym.typ != "vector" {
+		c.errorf("%s is not a vector", node.Left.String())
+		return
+	}
+	if sym.shape != nil && len(node.Indices) > len(sym.shape) {
+		c.errorf("wrong number of indices for %s: want=%d, got=%d",
+			node.Left.String(), len(sym.shape), len(node.Indices))
+	}
+}
+
+func (c *Checker) checkField(node *ast.FieldExpression) {
+	c.check(node.Left)
+
+	sym := c.symbolOf(node.Left)
+	if sym == nil {
+		return
+	}
+
+	if sym.kind != VARIABLE || sym.typ == "vector" || sym.fields == nil {
+		c.errorf("%s is not a record", node.Left.String())
+		return
+	}
+	if _, ok := sym.fields[node.Field.Value]; !ok {
+		c.errorf("%s has no field %s", sym.typ, node.Field.Value)
+	}
+}
+
+// checkWriteItem reports format suffixes that do not fit the value: sizes
+// must be integers, a precision needs a number and a base an integer.
+func (c *Checker) checkWriteItem(node *ast.WriteItem) {
+	c.check(node.Value)
+	typ := c.typeOf(node.Value)
+
+	for _, size := range []ast.Expression{node.Width, node.Precision} {
+		if size == nil {
+			continue
+		}
+		c.check(size)
+		if t := c.typeOf(size); t != "" && t != "integer" {
+			c.errorf("format size must be integer, got %s", t)
+		}
+	}
+
+	// the parser takes B, C or H after a colon as a base even if a variable
+	// or constant has that name, so such a name cannot be a format size
+	if _, ok := c.lookup(node.Base); node.Base != "" && ok {
+		c.errorf("base suffix %s hides the declared name %s", node.Base, node.Base)
+	}
+
+	switch {
+	case c.isComposite(typ):
+		c.errorf("cannot format %s", typ)
+	case node.Base != "" && typ != "" && typ != "integer":
+		c.errorf("base suffix %s needs an integer, got %s", node.Base, typ)
+	case node.Precision != nil && typ != "" && !isNumber(typ):
+		c.errorf("precision needs a real, got %s", typ)
+	}
+}
+
+// checkAssignment reports values that cannot be stored in the variable,
+// element or field sym describes: a vector or record of another type or
+// shape, or a scalar that is neither of its type nor an integer for a real.
+func (c *Checker) checkAssignment(name string, sym *symbol, value ast.Expression) {
+	if sym != nil && sym.kind == VARIABLE && holdsFile(sym) {
+		c.errorf("cannot assign to %s: files cannot be copied", name)
+		return
+	}
+
+	typ := c.typeOf(value)
+	if typ == "" || sym == nil || sym.kind != VARIABLE || sym.typ == "" {
+		return
+	}
+	if !c.isComposite(sym.typ) && !c.isComposite(typ) {
+		if !assignable(sym.typ, typ) {
+			c.errorf("type mismatch in assignment to %s: want %s, got %s", name, sym.typ, typ)
+		}
+		return
+	}
+
+	if sym.typ != typ {
+		c.errorf("type mismatch in assignment to %s: want %s, got %s", name, sym.typ, typ)
+		return
+	}
+
+	src := c.symbolOf(value)
+	if typ != "vector" || src == nil || sym.shape == nil || src.shape == nil {
+		return
+	}
+
+	if !sameShape(sym, src) {
+		c.errorf("shape mismatch in assignment to %s: want %s, got %s",
+			name, vectorType(sym), vectorType(src))
+	}
+}
+
+func (c *Checker) isComposite(typ string) bool {
+	return typ == "vector" || c.isRecordType(typ)
+}
+
+func (c *Checker) labelVisible(name string) bool {
+	for _, labels := range c.labels {
+		if labels[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) checkForVariable(variable *ast.Identifier) {
+	sym, ok := c.lookup(variable.Value)
+	if !ok {
+		c.errorf("undeclared for loop variable %s", variable.Value)
+		return
+	}
+
+	if sym.kind == CONSTANT {
+		c.errorf("for loop variable %s is a constant", variable.Value)
+		return
+	}
+
+	if sym.kind != VARIABLE || sym.typ != "integer" {
+		c.errorf("for loop variable %s must be integer, got %s", variable.Value, sym.typ)
+	}
+}
+
+func (c *Checker) errorf(format string, a ...interface{}) {
+	c.errors = append(c.errors, fmt.Sprintf(format, a...))
+}
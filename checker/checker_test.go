@@ -0,0 +1,505 @@
+package checker
+
+import (
+	"interp/lexer"
+	"interp/parser"
+	"testing"
+)
+
+func TestExitOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"a: integer; loop begin exit; end;", []string{}},
+		{"a: integer; loop begin a := a + 1; exit when a > 5; end;", []string{}},
+		{"loop begin if 1 then exit; end; end;", []string{}},
+		{"exit;", []string{"exit statement outside of a loop"}},
+		{"begin exit when 1; end;", []string{"exit statement outside of a loop"}},
+		{"loop begin end; if 1 then exit; end;", []string{"exit statement outside of a loop"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestLoopForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"i: integer; for i := 1 to 10 do exit when i > 5; end;", []string{}},
+		{"i: integer; while i < 10 do i := i + 1; exit; end;", []string{}},
+		{"i: integer; repeat i := i + 1; exit when i = 3; until i > 10;", []string{}},
+		{"for i := 1 to 10 do end;", []string{"undeclared for loop variable i"}},
+		{"x: real; for x := 1 to 10 do end;", []string{"for loop variable x must be integer, got real"}},
+		{"i, x: real; for i := 1 to 10 do end;", []string{"for loop variable i must be integer, got real"}},
+		{"x: real; i, j: integer; for j := 1 to 10 do end;", []string{}},
+		{"v: vector[3] of integer; for v := 1 to 3 do end;", []string{"for loop variable v must be integer, got vector"}},
+		{"i: integer; while i < 10 do end; exit;", []string{"exit statement outside of a loop"}},
+		{"i: integer; for i := 1.5 to 3 do end;", []string{"for loop bound 1.5 must be integer, got real"}},
+		{`i: integer; s: string; for i := 1 downto s do end;`, []string{"for loop bound s must be integer, got string"}},
+		{"i, n: integer; for i := n div 2 to n * 2 do end;", []string{}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"a: integer; again: a := a + 1; goto again;", []string{}},
+		{"begin goto fin; fin: end;", []string{}},
+		{"begin loop begin goto fin; end; fin: end;", []string{}},
+		{"goto nowhere;", []string{"undefined label nowhere"}},
+		{"begin inner: end; goto inner;", []string{"undefined label inner"}},
+		{"top: ; begin top: ; end; top: ;", []string{"duplicate label top"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestProcedures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"a: integer; procedure p(x: integer; var y: integer); begin y := x; end; p(1, a);", []string{}},
+		{"procedure p(n: integer); begin if n > 0 then p(n - 1); end; end; p(3);", []string{}},
+		{"p(1);", []string{"undefined procedure p"}},
+		{"a: integer; a(1);", []string{"a is not a procedure"}},
+		{"procedure p(x: integer); begin end; p(1, 2);", []string{"wrong number of arguments to p: want=1, got=2"}},
+		{"x: real; procedure p(n: integer); begin end; p(x);", []string{"argument 1 of p must be integer, got real"}},
+		{"procedure p(var n: integer); begin end; p(1 + 2);", []string{"argument 1 of p must be a variable, got (1 + 2)"}},
+		{"x: real; procedure p(var n: integer); begin end; p(x);", []string{"argument 1 of p must be a integer variable, got real"}},
+		{"procedure p(a, a: integer); begin end;", []string{"duplicate parameter a of p"}},
+		{"loop begin procedure p; begin exit; end; end;", []string{"exit statement outside of a loop"}},
+		{"procedure p; begin goto fin; end; fin: ;", []string{"undefined label fin"}},
+		{"i: real; procedure p; begin i: integer; for i := 1 to 2 do end; end;", []string{}},
+		{"procedure p; begin write 1; end; p; p();", []string{}},
+		{"procedure p(x: integer); begin end; p;", []string{"wrong number of arguments to p: want=1, got=0"}},
+		{"procedure p; begin end; write p;", []string{"procedure p does not return a value"}},
+		{"function f: integer; begin return 1; end; a: integer; a := f + f();", []string{}},
+		{"function f: integer; begin return 1; end; s: string; s := f;", []string{"type mismatch in assignment to s: want string, got integer"}},
+		{"function f(x: integer): integer; begin return x; end; write f;", []string{"wrong number of arguments to f: want=1, got=0"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func testCheckerErrors(t *testing.T, input string, expected []string) {
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+
+	errors := New().Check(program)
+	if len(errors) != len(expected) {
+		t.Fatalf("wrong number of errors for %q. got=%v, want=%v",
+			input, errors, expected)
+	}
+
+	for i, msg := range expected {
+		if errors[i] != msg {
+			t.Errorf("wrong error for %q. got=%q, want=%q", input, errors[i], msg)
+		}
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"function f(n: integer): integer; begin return n * 2; end; a: integer; a := f(1) + 1;", []string{}},
+		{"function f(n: integer): integer; begin if n > 0 then return f(n - 1); end; return 0; end;", []string{}},
+		{"function f(n: integer): integer; begin end; f(1);", []string{}},
+		{"a: integer; a := f(1);", []string{"undefined function f"}},
+		{"procedure p; begin end; a: integer; a := p() + 1;", []string{"p is not a function"}},
+		{"a, b: integer; b := a(1);", []string{"a is not a function"}},
+		{"function f(n: integer): integer; begin return n; end; a: integer; a := f();", []string{"wrong number of arguments to f: want=1, got=0"}},
+		{"x: real; function f(n: integer): integer; begin return n; end; a: integer; a := f(x);", []string{"argument 1 of f must be integer, got real"}},
+		{"function f(x: real): integer; begin return x; end;", []string{"function f must return integer, got real"}},
+		{"function f: integer; begin return; end;", []string{"function f must return a value"}},
+		{"procedure p; begin return 1; end;", []string{"procedure p cannot return a value"}},
+		{"procedure p; begin return; end;", []string{}},
+		{"return 1;", []string{"return outside of a procedure or function"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestStringTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`s: string; s := "a" + s; write s, skip;`, []string{}},
+		{`s: string; write s = "x";`, []string{}},
+		{`s: string; a: integer; write s + a;`, []string{"type mismatch: string + integer"}},
+		{`write 1 < "a";`, []string{"type mismatch: integer < string"}},
+		{`s: string; write s - "a";`, []string{"unknown operator: string - string"}},
+		{`s: string; write s * s, s div s;`, []string{"unknown operator: string * string", "unknown operator: string div string"}},
+		{`s: string; write s and s;`, []string{"unknown operator: string and string"}},
+		{`s: string; write -s;`, []string{"unknown operator: -string"}},
+		{`procedure p(s: string); begin end; p(1);`, []string{"argument 1 of p must be string, got integer"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"x: real; x := sqrt(2) + sin(x);", []string{}},
+		{"a: integer; a := abs(a) + trunc(2.5) + round(a); odd(a);", []string{}},
+		{"procedure p(n: integer); begin end; p(round(2.5));", []string{}},
+		{"procedure p(n: integer); begin end; p(sqrt(4));", []string{"argument 1 of p must be integer, got real"}},
+		{"procedure p(n: integer); begin end; p(abs(1.5));", []string{"argument 1 of p must be integer, got real"}},
+		{"sqrt(1, 2);", []string{"wrong number of arguments to sqrt: want=1, got=2"}},
+		{`sqrt("x");`, []string{"argument 1 of sqrt must be integer or real, got string"}},
+		{"odd(1.5);", []string{"argument 1 of odd must be integer, got real"}},
+		{"function abs(s: string): string; begin return s; end; abs(\"x\");", []string{}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestCase(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"x: integer; case x of 1, 2: x := 0; 3: ; else x := 1; end;", []string{}},
+		{"x: integer; case x of 1: ; 2, 1: ; end;", []string{"duplicate case label 1"}},
+		{"x: integer; case x of 10: ; 1010B: ; 0AH: ; end;", []string{"duplicate case label 10", "duplicate case label 10"}},
+		{"x: integer; case x of -1: ; 1: ; end;", []string{}},
+		{`case "a" of 1: ; end;`, []string{"case expression must be integer, got string"}},
+		{"x: integer; case x of 1: goto fin; end; fin: ;", []string{}},
+		{"x: integer; case x of 1: exit; end;", []string{"exit statement outside of a loop"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestConstants(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"const n = 10; const m = n * 2 - 1; v: vector[m] of integer;", []string{}},
+		{"const pi = 3.14; x: real; x := pi * 2.0;", []string{}},
+		{"x, y: integer; case x of y: ; end;", []string{"case label y is not an integer constant"}},
+		{"const n = 5; x: integer; case x of 5: ; n: ; end;", []string{"duplicate case label 5"}},
+		{"const n = 5; n := 6;", []string{"cannot assign to constant n"}},
+		{"const n = 5; read n;", []string{"cannot read into constant n"}},
+		{"const n = 5; for n := 1 to 2 do end;", []string{"for loop variable n is a constant"}},
+		{"const n = 5; procedure p(var x: integer); begin end; p(n);", []string{"argument 1 of p must be a variable, got n"}},
+		{"x: integer; const n = x + 1;", []string{"value of constant n is not a constant expression"}},
+		{"x: integer; v: vector[x] of integer;", []string{"size of vector v must be a positive integer constant, got x"}},
+		{"const n = -2; v: vector[n] of integer;", []string{"size of vector v must be a positive integer constant, got n"}},
+		{`const s = "a"; v: vector[s] of integer;`, []string{"size of vector v must be a positive integer constant, got s"}},
+		{"const n = 1; procedure p; begin n: integer; n := 2; end;", []string{}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestRedeclarations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"const n = 3; n: integer; n := 4;", []string{"n is already declared"}},
+		{"a: integer; a: real;", []string{"a is already declared"}},
+		{"a: integer; a: vector[2] of integer;", []string{"a is already declared"}},
+		{"type point = record x: real end; type point = record y: real end;", []string{"point is already declared"}},
+		{"a: integer; procedure a; begin end;", []string{"a is already declared"}},
+		{"function f: integer; begin return 1; end; procedure f; begin end;", []string{"f is already declared"}},
+		{"procedure p(x: integer); begin x: real; end;", []string{"x is already declared"}},
+		{"a: integer; procedure p(a: real); begin end; procedure q; begin a: string; end;", []string{}},
+		{"random: integer; random := 2;", []string{}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+
+	// the declarations of a program with errors are not kept
+	c := New()
+	for _, tt := range []struct {
+		input  string
+		errors int
+	}{
+		{`a: integer; a := "x";`, 1},
+		{"a: integer;", 0},
+		{"a: integer;", 1},
+	} {
+		program := parser.New(lexer.New(tt.input)).ParseProgram()
+		if errors := c.Check(program); len(errors) != tt.errors {
+			t.Errorf("wrong errors for %q. got=%v, want %d", tt.input, errors, tt.errors)
+		}
+	}
+}
+
+func TestVectors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"m: vector[2, 3] of real; x: real; i: integer; m[i, 1] := 1.0; x := m[2, i];", []string{}},
+		{"const n = 2; m: vector[n] of vector[n] of integer; m[1, 2] := m[2, 1];", []string{}},
+		{"m: vector[2, 3] of real; m[1] := 1.0;", []string{"type mismatch in assignment to m[1]: want vector, got real"}},
+		{"m: vector[2] of vector[3] of real; r: vector[3] of real; m[1][2] := 1.0; r := m[2]; m[1] := r;", []string{}},
+		{"m: vector[2, 3] of real; r: vector[2] of real; m[1] := r;",
+			[]string{"shape mismatch in assignment to m[1]: want vector[3] of real, got vector[2] of real"}},
+		{"m: vector[2, 3] of real; m[1][2][3];", []string{"m[1][2] is not a vector"}},
+		{"m: vector[2, 3] of real; m[1, 2, 3];", []string{"wrong number of indices for m: want=2, got=3"}},
+		{"v: vector[0] of integer;", []string{"size of vector v must be a positive integer constant, got 0"}},
+		{"v: vector[3] of integer; x: real; read v[1, 1], x;", []string{"wrong number of indices for v: want=1, got=2"}},
+		{"x: integer; x[1] := 2;", []string{"x is not a vector"}},
+		{"x: integer; v: vector[3, x] of integer;", []string{"size of vector v must be a positive integer constant, got x"}},
+		{`v: vector[2] of string; s: string; s := v[1] + "!";`, []string{}},
+		{"v: vector[2] of string; x: integer; x := v[1] + 1;", []string{"type mismatch: string + integer"}},
+		{"v: vector[5] of integer; i: integer; v[i + 1] := v[2 * i - 1] + v[v[1]];", []string{}},
+		{"v: vector[5] of integer; x: real; v[x] := 1;", []string{"vector index must be integer, got real"}},
+		{`v: vector[5] of integer; v["a" + "b"];`, []string{"vector index must be integer, got string"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestVectorAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"a, b: vector[3] of integer; a := b;", []string{}},
+		{"const n = 3; a: vector[n] of integer; b: vector[3] of integer; a := b; read a; write a;", []string{}},
+		{"a: vector[3] of integer; b: vector[4] of integer; a := b;",
+			[]string{"shape mismatch in assignment to a: want vector[3] of integer, got vector[4] of integer"}},
+		{"a: vector[2, 2] of real; b: vector[4] of real; a := b;",
+			[]string{"shape mismatch in assignment to a: want vector[2, 2] of real, got vector[4] of real"}},
+		{"a: vector[2] of real; b: vector[2] of string; a := b;",
+			[]string{"shape mismatch in assignment to a: want vector[2] of real, got vector[2] of string"}},
+		{"a: vector[2] of integer; a := 5;", []string{"type mismatch in assignment to a: want vector, got integer"}},
+		{"a: vector[2] of integer; x: integer; x := a;", []string{"type mismatch in assignment to x: want integer, got vector"}},
+		{"a: vector[2] of integer; x: integer; x := a[1];", []string{}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"type point = record x, y: real end; p: point; x: real; x := p.x + p.y;", []string{}},
+		{`type point = record x, y: real end;
+		  type shape = record name: string; pts: vector[3] of point end;
+		  s: shape; s.pts[1].x := 1.0; s.name := "tri";`, []string{}},
+		{"type point = record x, y: real end; p, q: point; p := q;", []string{}},
+		{"type point = record x, y: real end; procedure move(var p: point); begin p.x := 1.0; end; q: point; move(q);", []string{}},
+		{"type point = record x, y: real end; p: point; p.z := 1.0;", []string{"point has no field z"}},
+		{"type point = record x, x: real end;", []string{"duplicate field x of point"}},
+		{"type line = record a, b: point end;", []string{"undefined type point", "undefined type point"}},
+		{"x: integer; type r = record f: x end;", []string{"x is not a type"}},
+		{"x: integer; x.y := 1;", []string{"x is not a record"}},
+		{"type point = record x, y: real end; p: point; p[1];", []string{"p is not a vector"}},
+		{"type point = record x, y: real end; pts: vector[2] of point; pts.x;", []string{"pts is not a record"}},
+		{`type a = record x: real end; type b = record x: real end; p: a; q: b; p := q;`,
+			[]string{"type mismatch in assignment to p: want a, got b"}},
+		{"type point = record x, y: real end; p: point; p := 1;", []string{"type mismatch in assignment to p: want point, got integer"}},
+		{"type point = record x, y: real end; p: point; x: real; x := p;", []string{"type mismatch in assignment to x: want real, got point"}},
+		{"type point = record x, y: real end; p, q: point; write p + q;", []string{"unknown operator: point + point"}},
+		{"type point = record x, y: real end; p, q: point; write p = q;", []string{"unknown operator: point = point"}},
+		{"type point = record x, y: real end; p: point; write p * 2;", []string{"type mismatch: point * integer"}},
+		{"type point = record x, y: real end; p: point; read p, p.x;", []string{"cannot read p of type point"}},
+		{"type point = record x, y: real end; v: vector[2] of point; read v, v[1].x;", []string{"cannot read v with elements of type point"}},
+		{"type point = record x, y: real end; s: string; s := 1 + point.x;", []string{"point is not a record"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestChars(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"c: char; n: integer; c := 'a'; n := ord(c); c := chr(n + 1);", []string{}},
+		{`c: char; s: string; read c; s := s + c; s := 'x' + s;`, []string{}},
+		{"const nl = '\\n'; c: char; c := nl;", []string{}},
+		{"c: char; c < 'z';", []string{}},
+		{"c: char; c + 1;", []string{"type mismatch: char + integer"}},
+		{"c: char; c := 'a' + 'b';", []string{"unknown operator: char + char"}},
+		{"c: char; c - 'a';", []string{"unknown operator: char - char"}},
+		{"c: char; c * c;", []string{"unknown operator: char * char"}},
+		{`c: char; c = "a";`, []string{"type mismatch: char = string"}},
+		{`ord("a");`, []string{"argument 1 of ord must be char, got string"}},
+		{"chr('a');", []string{"argument 1 of chr must be integer, got char"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestDivision(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"n: integer; n := 7 div 2;", []string{}},
+		{"r: real; r := 7 / 2;", []string{}},
+		{"const k = 6 div 2; v: vector[k] of integer; v[3] := 1;", []string{}},
+		{"n: integer; n := 7 / 2;", []string{"type mismatch in assignment to n: want integer, got real"}},
+		{"r: real; r div 2;", []string{"unknown operator: real div integer"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+
+	c := New()
+	c.SetIntegerDivide(true)
+	program := parser.New(lexer.New("n: integer; n := 7 / 2;")).ParseProgram()
+	if errors := c.Check(program); len(errors) != 0 {
+		t.Errorf("unexpected errors with integer division: %v", errors)
+	}
+}
+
+func TestWriteItems(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"n: integer; r: real; write n:8, r:8:2, n:4:1, n:H, n:8:B;", []string{}},
+		{`write "x":4, 'c':2;`, []string{}},
+		{"r: real; write r:H;", []string{"base suffix H needs an integer, got real"}},
+		{"n, H: integer; H := 6; write n:H;", []string{"base suffix H hides the declared name H"}},
+		{"const B = 8; n: integer; write n:4:B;", []string{"base suffix B hides the declared name B"}},
+		{"n: integer; procedure p(C: integer); begin write n:C; end;", []string{"base suffix C hides the declared name C"}},
+		{`write "x":4:1;`, []string{"precision needs a real, got string"}},
+		{"r: real; write 1:r;", []string{"format size must be integer, got real"}},
+		{"v: vector[2] of integer; write v:4;", []string{"cannot format vector"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`f: file; n: integer; assign(f, "x"); reset(f); while not eof(f) do read f, n; end; close(f);`, []string{}},
+		{`f: file; assign(f, "x"); rewrite(f); write f, 1, skip; close(f);`, []string{}},
+		{"procedure p(var f: file); begin close(f); end;", []string{}},
+		{`f: file; assign(f, 1);`, []string{"argument 2 of assign must be string, got integer"}},
+		{`n: integer; reset(n);`, []string{"argument 1 of reset must be file, got integer"}},
+		{`f, g: file; read f, g;`, []string{"cannot read g of type file"}},
+		{`v: vector[2] of file; read v;`, []string{"cannot read v with elements of type file"}},
+		{`f: file; v: vector[2] of file; read f, v;`, []string{"cannot read v with elements of type file"}},
+		{`f: file; write 1, f;`, []string{"cannot write f of type file"}},
+		{`f, g: file; write f + g;`, []string{"unknown operator: file + file"}},
+		{`f, g: file; f := g;`, []string{"cannot assign to f: files cannot be copied"}},
+		{`v: vector[2] of file; f: file; v[1] := f;`, []string{"cannot assign to v[1]: files cannot be copied"}},
+		{`type log = record name: string; out: file end; a, b: log; a := b; a.name := "x";`,
+			[]string{"cannot assign to a: files cannot be copied"}},
+		{"procedure p(f: file); begin end;", []string{"parameter f of p must be var: files cannot be copied"}},
+		{"type log = record out: file end; procedure p(l: log); begin end;",
+			[]string{"parameter l of p must be var: files cannot be copied"}},
+		{`f: file; write -f;`, []string{"unknown operator: -file"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestAssertAndHalt(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"n: integer; assert n >= 0; halt(n);", []string{}},
+		{`assert "a" < 1;`, []string{"type mismatch: string < integer"}},
+		{`halt("x");`, []string{"argument 1 of halt must be integer, got string"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"n: integer; randomize(); n := random(6) + 1;", []string{}},
+		{"randomize; write random;", []string{"wrong number of arguments to random: want=1, got=0"}},
+		{"r: real; r := random(10) / 10;", []string{}},
+		{"random(1.5);", []string{"argument 1 of random must be integer, got real"}},
+		{"randomize(1);", []string{"wrong number of arguments to randomize: want=0, got=1"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"r: real; n: integer; r := n; r := n + 1.5; n := trunc(r); n := round(r * 2);", []string{}},
+		{"r: real; r := real(3) / 2;", []string{}},
+		{"v: vector[2] of real; v[1] := 1;", []string{}},
+		{"function f(x: real): real; begin return x; end; f(1);", []string{}},
+		{"n: integer; n := 1.5;", []string{"type mismatch in assignment to n: want integer, got real"}},
+		{"n: integer; n := n + 0.5;", []string{"type mismatch in assignment to n: want integer, got real"}},
+		{"v: vector[2] of integer; v[1] := 0.5;", []string{"type mismatch in assignment to v[1]: want integer, got real"}},
+		{"type p = record n: integer; end; a: p; a.n := 0.5;", []string{"type mismatch in assignment to a.n: want integer, got real"}},
+		{`n: integer; n := "1";`, []string{"type mismatch in assignment to n: want integer, got string"}},
+		{`real("1");`, []string{"argument 1 of real must be integer or real, got string"}},
+	}
+
+	for _, tt := range tests {
+		testCheckerErrors(t, tt.input, tt.expected)
+	}
+}
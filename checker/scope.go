@@ -0,0 +1,47 @@
+package checker
+
+import (
+	"interp/ast"
+	"math/big"
+)
+
+type symbolKind int
+
+const (
+	VARIABLE symbolKind = iota
+	CONSTANT
+	PROCEDURE
+	FUNCTION
+	BUILTIN
+	TYPE
+)
+
+type symbol struct {
+	kind   symbolKind
+	typ    string             // declared type, or the result type of a function
+	params []*ast.Parameter   // procedures and functions only
+	sig    *signature         // builtins only
+	value  *big.Int           // integer constants only
+	elem   string             // element type of a vector
+	shape  []*big.Int         // sizes of the dimensions of a vector, nil if not constant
+	fields map[string]*symbol // fields of a record, or of the records in a vector
+}
+
+// declare adds name to the innermost scope. A name can be declared once per
+// scope; only the builtins may be shadowed.
+func (c *Checker) declare(name string, sym *symbol) {
+	scope := c.scopes[len(c.scopes)-1]
+	if prev, ok := scope[name]; ok && prev.kind != BUILTIN {
+		c.errorf("%s is already declared", name)
+	}
+	scope[name] = sym
+}
+
+func (c *Checker) lookup(name string) (*symbol, bool) {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if sym, ok := c.scopes[i][name]; ok {
+			return sym, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,241 @@
+package checker
+
+import (
+	"interp/ast"
+	"math/big"
+	"strings"
+)
+
+// typeOf infers the type of an expression, or returns "" when it cannot be
+// known before the program runs.
+func (c *Checker) typeOf(node ast.Expression) string {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral:
+		return "integer"
+
+	case *ast.FloatLiteral:
+		return "real"
+
+	case *ast.StringLiteral:
+		return "string"
+
+	case *ast.CharLiteral:
+		return "char"
+
+	case *ast.Identifier:
+		if sym, ok := c.lookup(node.Value); ok {
+			switch sym.kind {
+			case VARIABLE, CONSTANT, FUNCTION:
+				return sym.typ
+			case BUILTIN:
+				return sym.sig.resultType(nil)
+			}
+		}
+
+	case *ast.IndexExpression, *ast.FieldExpression:
+		if sym := c.symbolOf(node); sym != nil && sym.kind == VARIABLE {
+			return sym.typ
+		}
+
+	case *ast.CallExpression:
+		if ident, ok := node.Function.(*ast.Identifier); ok {
+			if sym, ok := c.lookup(ident.Value); ok {
+				switch sym.kind {
+				case FUNCTION:
+					return sym.typ
+				case BUILTIN:
+					args := make([]string, len(node.Arguments))
+					for i, arg := range node.Arguments {
+						args[i] = c.typeOf(arg)
+					}
+					return sym.sig.resultType(args)
+				}
+			}
+		}
+
+	case *ast.PrefixExpression:
+		if node.Operator == "not" {
+			return "integer"
+		}
+		return c.typeOf(node.Right)
+
+	case *ast.InfixExpression:
+		switch node.Operator {
+		case "+", "-", "*", "/":
+			left, right := c.typeOf(node.Left), c.typeOf(node.Right)
+			if node.Operator == "/" && left == "integer" && right == "integer" && !c.integerDivide {
+				return "real"
+			}
+			if isNumber(left) && isNumber(right) {
+				if left == right {
+					return left
+				}
+				return "real"
+			}
+			if node.Operator == "+" && isText(left) && isText(right) && (left == "string" || right == "string") {
+				return "string"
+			}
+		default:
+			return "integer"
+		}
+	}
+
+	return ""
+}
+
+// symbolOf describes what a variable, vector element or record field
+// refers to, or returns nil when that is not known.
+func (c *Checker) symbolOf(node ast.Expression) *symbol {
+	switch node := node.(type) {
+	case *ast.Identifier:
+		if sym, ok := c.lookup(node.Value); ok {
+			return sym
+		}
+
+	case *ast.IndexExpression:
+		base := c.symbolOf(node.Left)
+		if base == nil || base.kind != VARIABLE || base.typ != "vector" {
+			break
+		}
+		// fewer indices than dimensions select a row
+		if len(node.Indices) < len(base.shape) {
+			row := *base
+			row.shape = base.shape[len(node.Indices):]
+			return &row
+		}
+		return &symbol{kind: VARIABLE, typ: base.elem, fields: base.fields}
+
+	case *ast.FieldExpression:
+		if base := c.symbolOf(node.Left); base != nil && base.kind == VARIABLE && base.typ != "vector" {
+			return base.fields[node.Field.Value]
+		}
+	}
+
+	return nil
+}
+
+// isText reports whether values of type typ can be joined with +: a string
+// may be concatenated with a string or a character.
+func isText(typ string) bool {
+	return typ == "string" || typ == "char"
+}
+
+// holdsFile reports whether a variable is a file or has one among its
+// elements or fields. Such a variable cannot be copied, since the copy
+// would share the open file.
+func holdsFile(sym *symbol) bool {
+	if sym.typ == "file" || sym.elem == "file" {
+		return true
+	}
+	for _, field := range sym.fields {
+		if holdsFile(field) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumber(typ string) bool {
+	return typ == "integer" || typ == "real"
+}
+
+// assignable reports whether a value of type from may be stored in a
+// variable of type to: the same type, or an integer widened to a real.
+// Unknown types are left to the evaluator.
+func assignable(to, from string) bool {
+	return from == "" || to == from || to == "real" && from == "integer"
+}
+
+// sameShape reports whether two vectors have the same element type and
+// sizes. Sizes that are not constant match anything.
+func sameShape(a, b *symbol) bool {
+	if a.elem != b.elem || len(a.shape) != len(b.shape) {
+		return false
+	}
+	for i := range a.shape {
+		if a.shape[i] != nil && b.shape[i] != nil && a.shape[i].Cmp(b.shape[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// vectorType describes a vector the way it is declared.
+func vectorType(sym *symbol) string {
+	dims := []string{}
+	for _, size := range sym.shape {
+		if size == nil {
+			dims = append(dims, "?")
+			continue
+		}
+		dims = append(dims, size.String())
+	}
+	return "vector[" + strings.Join(dims, ", ") + "] of " + sym.elem
+}
+
+// constantValue folds an integer constant expression: literals, named
+// constants and arithmetic on them.
+func (c *Checker) constantValue(node ast.Expression) (*big.Int, bool) {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return node.Big, true
+		}
+		return big.NewInt(node.Value), true
+
+	case *ast.Identifier:
+		if sym, ok := c.lookup(node.Value); ok && sym.kind == CONSTANT && sym.value != nil {
+			return sym.value, true
+		}
+
+	case *ast.PrefixExpression:
+		if node.Operator != "-" {
+			return nil, false
+		}
+		if value, ok := c.constantValue(node.Right); ok {
+			return new(big.Int).Neg(value), true
+		}
+
+	case *ast.InfixExpression:
+		left, ok := c.constantValue(node.Left)
+		if !ok {
+			return nil, false
+		}
+		right, ok := c.constantValue(node.Right)
+		if !ok {
+			return nil, false
+		}
+
+		switch node.Operator {
+		case "+":
+			return new(big.Int).Add(left, right), true
+		case "-":
+			return new(big.Int).Sub(left, right), true
+		case "*":
+			return new(big.Int).Mul(left, right), true
+		case "/", "div":
+			if right.Sign() != 0 && (node.Operator == "div" || c.integerDivide) {
+				return new(big.Int).Quo(left, right), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// isConstant reports whether an expression can be evaluated before the
+// program runs: it is built from literals and named constants only.
+func (c *Checker) isConstant(node ast.Expression) bool {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.CharLiteral:
+		return true
+	case *ast.Identifier:
+		sym, ok := c.lookup(node.Value)
+		return ok && sym.kind == CONSTANT
+	case *ast.PrefixExpression:
+		return c.isConstant(node.Right)
+	case *ast.InfixExpression:
+		return c.isConstant(node.Left) && c.isConstant(node.Right)
+	}
+	return false
+}
@@ -0,0 +1,267 @@
+package evaluator
+
+import (
+	"interp/object"
+	"math"
+	"math/big"
+)
+
+var builtins = map[string]*object.Builtin{
+	"abs":   {Name: "abs", Fn: builtinAbs},
+	"sqr":   {Name: "sqr", Fn: builtinSqr},
+	"sqrt":  {Name: "sqrt", Fn: builtinSqrt},
+	"sin":   {Name: "sin", Fn: realBuiltin("sin", math.Sin)},
+	"cos":   {Name: "cos", Fn: realBuiltin("cos", math.Cos)},
+	"exp":   {Name: "exp", Fn: realBuiltin("exp", math.Exp)},
+	"ln":    {Name: "ln", Fn: builtinLn},
+	"trunc": {Name: "trunc", Fn: roundingBuiltin("trunc", math.Trunc)},
+	"round": {Name: "round", Fn: roundingBuiltin("round", math.Round)},
+	"odd":   {Name: "odd", Fn: builtinOdd},
+	"min":   {Name: "min", Fn: extremumBuiltin("min", "<")},
+	"max":   {Name: "max", Fn: extremumBuiltin("max", ">")},
+	"ord":   {Name: "ord", Fn: builtinOrd},
+	"chr":   {Name: "chr", Fn: builtinChr},
+	"real":  {Name: "real", Fn: builtinReal},
+
+	"assign":  {Name: "assign", Fn: builtinAssign},
+	"reset":   {Name: "reset", Fn: builtinReset},
+	"rewrite": {Name: "rewrite", Fn: builtinRewrite},
+	"close":   {Name: "close", Fn: builtinClose},
+	"eof":     {Name: "eof", Fn: builtinEof},
+
+	"halt": {Name: "halt", Fn: builtinHalt},
+
+	"random":    {Name: "random", Fn: builtinRandom},
+	"randomize": {Name: "randomize", Fn: builtinRandomize},
+}
+
+func builtinAbs(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("abs", args, 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		if arg.Big != nil && arg.Big.Sign() < 0 || arg.Big == nil && arg.Value < 0 {
+			return evalMinusPrefixOperatorExpression(arg, rt)
+		}
+		return arg
+	case *object.Real:
+		return &object.Real{Value: math.Abs(arg.Value)}
+	default:
+		return numberArgumentError("abs", arg)
+	}
+}
+
+func builtinSqr(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("sqr", args, 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer:
+		return evalIntegerInfixExpression("*", arg, arg, rt)
+	case *object.Real:
+		return &object.Real{Value: arg.Value * arg.Value}
+	default:
+		return numberArgumentError("sqr", arg)
+	}
+}
+
+func builtinSqrt(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("sqrt", args, 1); err != nil {
+		return err
+	}
+
+	x, err := realArgument("sqrt", args[0])
+	if err != nil {
+		return err
+	}
+	if x < 0 {
+		return newError("sqrt of negative number %s", args[0].Inspect())
+	}
+
+	return &object.Real{Value: math.Sqrt(x)}
+}
+
+func builtinLn(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("ln", args, 1); err != nil {
+		return err
+	}
+
+	x, err := realArgument("ln", args[0])
+	if err != nil {
+		return err
+	}
+	if x <= 0 {
+		return newError("ln of non-positive number %s", args[0].Inspect())
+	}
+
+	return &object.Real{Value: math.Log(x)}
+}
+
+func builtinOdd(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("odd", args, 1); err != nil {
+		return err
+	}
+
+	arg, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("argument to odd must be INTEGER, got %s", args[0].Type())
+	}
+
+	if arg.Big != nil {
+		return nativeCmp(arg.Big.Bit(0) == 1)
+	}
+	return nativeCmp(arg.Value%2 != 0)
+}
+
+// realBuiltin wraps a math function defined for every real number.
+func realBuiltin(name string, fn func(float64) float64) object.BuiltinFunction {
+	return func(rt *object.Runtime, args ...object.Object) object.Object {
+		if err := checkArity(name, args, 1); err != nil {
+			return err
+		}
+
+		x, err := realArgument(name, args[0])
+		if err != nil {
+			return err
+		}
+
+		return &object.Real{Value: fn(x)}
+	}
+}
+
+// roundingBuiltin converts a real to an integer after rounding it with fn.
+// Integers are returned unchanged.
+func roundingBuiltin(name string, fn func(float64) float64) object.BuiltinFunction {
+	return func(rt *object.Runtime, args ...object.Object) object.Object {
+		if err := checkArity(name, args, 1); err != nil {
+			return err
+		}
+
+		switch arg := args[0].(type) {
+		case *object.Integer:
+			return arg
+		case *object.Real:
+			return realToInteger(name, fn(arg.Value), rt)
+		default:
+			return numberArgumentError(name, arg)
+		}
+	}
+}
+
+// extremumBuiltin returns the argument for which the other one does not
+// satisfy operator. An integer compared with a real is widened.
+func extremumBuiltin(name, operator string) object.BuiltinFunction {
+	return func(rt *object.Runtime, args ...object.Object) object.Object {
+		if err := checkArity(name, args, 2); err != nil {
+			return err
+		}
+
+		for _, arg := range args {
+			if arg.Type() != object.INTEGER_OBJ && arg.Type() != object.REAL_OBJ {
+				return numberArgumentError(name, arg)
+			}
+		}
+		a, b := args[0], args[1]
+		if a.Type() != b.Type() {
+			a, b = toReal(a), toReal(b)
+		}
+
+		if isTruthy(evalInfixExpression(operator, b, a, rt)) {
+			return b
+		}
+		return a
+	}
+}
+
+func realToInteger(name string, x float64, rt *object.Runtime) object.Object {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return newError("%s of %g out of integer range", name, x)
+	}
+
+	if rt.Arithmetic == object.ARITH_BIG {
+		value, _ := big.NewFloat(x).Int(nil)
+		return object.NewBigInteger(value)
+	}
+
+	if x < math.MinInt64 || x >= math.MaxInt64 {
+		return newError("%s of %g out of integer range", name, x)
+	}
+
+	return fitInteger(rt, int64(x), false,
+		"%s of %g out of range for %d-bit integers", name, x, rt.WordSize)
+}
+
+// realArgument accepts an integer or real argument as a float64.
+func realArgument(name string, arg object.Object) (float64, *object.Error) {
+	switch arg := arg.(type) {
+	case *object.Integer:
+		if arg.Big != nil {
+			x, _ := new(big.Float).SetInt(arg.Big).Float64()
+			return x, nil
+		}
+		return float64(arg.Value), nil
+	case *object.Real:
+		return arg.Value, nil
+	default:
+		return 0, numberArgumentError(name, arg)
+	}
+}
+
+// builtinHalt stops the program; the code becomes its exit status.
+func builtinHalt(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("halt", args, 1); err != nil {
+		return err
+	}
+
+	code, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("argument to halt must be INTEGER, got %s", args[0].Type())
+	}
+	if code.Big != nil || code.Value < 0 || code.Value > 255 {
+		return newError("exit code %s out of range 0..255", code.Inspect())
+	}
+
+	return &object.Halt{Code: int(code.Value)}
+}
+
+// builtinRandom returns an integer from 0 to n-1.
+func builtinRandom(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("random", args, 1); err != nil {
+		return err
+	}
+
+	n, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("argument to random must be INTEGER, got %s", args[0].Type())
+	}
+	if n.Big != nil || n.Value <= 0 {
+		return newError("argument to random must be positive, got %s", n.Inspect())
+	}
+
+	rt.Drawn = true
+	return &object.Integer{Value: rt.Rand.Int63n(n.Value)}
+}
+
+func builtinRandomize(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("randomize", args, 0); err != nil {
+		return err
+	}
+
+	rt.Randomize()
+	return NULL
+}
+
+func checkArity(name string, args []object.Object, want int) *object.Error {
+	if len(args) != want {
+		return newError("wrong number of arguments to %s: want=%d, got=%d",
+			name, want, len(args))
+	}
+	return nil
+}
+
+func numberArgumentError(name string, arg object.Object) *object.Error {
+	return newError("argument to %s must be INTEGER or REAL, got %s", name, arg.Type())
+}
@@ -0,0 +1,200 @@
+package evaluator
+
+import (
+	"interp/ast"
+	"interp/object"
+	"interp/token"
+)
+
+func evalCallStatement(
+	node *ast.CallStatement,
+	env *object.Environment,
+) object.Object {
+	callee, ok := env.Get(node.Name.Value)
+	if !ok {
+		builtin, ok := builtins[node.Name.Value]
+		if !ok {
+			return newError("procedure not found: %s", node.Name.Value)
+		}
+		callee = builtin
+	}
+
+	switch callee := callee.(type) {
+	case *object.Procedure:
+		return applyProcedure(callee, node.Arguments, env)
+	case *object.Function:
+		return applyFunction(callee, node.Arguments, env)
+	case *object.Builtin:
+		return applyBuiltin(callee, node.Arguments, env, node.Name.Token)
+	default:
+		return newError("not a procedure: %s", node.Name.Value)
+	}
+}
+
+func evalCallExpression(
+	node *ast.CallExpression,
+	env *object.Environment,
+) object.Object {
+	var callee object.Object
+	if ident, ok := node.Function.(*ast.Identifier); ok {
+		callee = lookupIdentifier(ident, env)
+	} else {
+		callee = Eval(node.Function, env)
+	}
+	if isError(callee) {
+		return callee
+	}
+
+	switch callee := callee.(type) {
+	case *object.Function:
+		return applyFunction(callee, node.Arguments, env)
+	case *object.Builtin:
+		tok := node.Token
+		if ident, ok := node.Function.(*ast.Identifier); ok {
+			tok = ident.Token
+		}
+		return applyBuiltin(callee, node.Arguments, env, tok)
+	case *object.Procedure:
+		return newError("procedure %s does not return a value", callee.Name)
+	default:
+		return newError("not a function: %s", node.Function.String())
+	}
+}
+
+func applyProcedure(
+	proc *object.Procedure,
+	args []ast.Expression,
+	env *object.Environment,
+) object.Object {
+	frame, errObj := bindArguments(proc.Name, proc.Parameters, proc.Env, args, env)
+	if errObj != nil {
+		return errObj
+	}
+
+	result := evalBody(proc.Name, proc.Body, frame)
+	if isError(result) {
+		return result
+	}
+
+	return NULL
+}
+
+func applyFunction(
+	fn *object.Function,
+	args []ast.Expression,
+	env *object.Environment,
+) object.Object {
+	frame, errObj := bindArguments(fn.Name, fn.Parameters, fn.Env, args, env)
+	if errObj != nil {
+		return errObj
+	}
+
+	result := evalBody(fn.Name, fn.Body, frame)
+	if isError(result) {
+		return result
+	}
+
+	returnValue, ok := result.(*object.ReturnValue)
+	if !ok || returnValue.Value == NULL {
+		return newError("function %s ended without returning a value", fn.Name)
+	}
+
+	if fn.ReturnType == "real" {
+		return toReal(returnValue.Value)
+	}
+	return returnValue.Value
+}
+
+// applyBuiltin evaluates the arguments and calls the Go function. Errors
+// raised by the builtin itself are reported at the call site tok.
+func applyBuiltin(
+	builtin *object.Builtin,
+	args []ast.Expression,
+	env *object.Environment,
+	tok token.Token,
+) object.Object {
+	vals := make([]object.Object, 0, len(args))
+	for _, arg := range args {
+		val := Eval(arg, env)
+		if isError(val) {
+			return val
+		}
+		vals = append(vals, val)
+	}
+
+	result := builtin.Fn(env.Runtime(), vals...)
+	if errObj, ok := result.(*object.Error); ok {
+		return newErrorAt(tok, "%s", errObj.Message)
+	}
+
+	return result
+}
+
+// evalBody runs the body of a procedure or function in its frame, keeping
+// track of the call depth so that runaway recursion becomes a runtime error
+// instead of exhausting the Go stack.
+func evalBody(
+	name string,
+	body *ast.BeginExpression,
+	frame *object.Environment,
+) object.Object {
+	rt := frame.Runtime()
+	if rt.CallDepth >= rt.MaxCallDepth {
+		return newError("call depth limit of %d exceeded in %s", rt.MaxCallDepth, name)
+	}
+
+	rt.CallDepth++
+	result := Eval(body, frame)
+	rt.CallDepth--
+
+	if jump, ok := result.(*object.Goto); ok {
+		return newError("label not found: %s", jump.Mark)
+	}
+
+	return result
+}
+
+// bindArguments creates the local frame of a call. Value parameters get the
+// evaluated argument, var parameters a reference to the caller's variable.
+func bindArguments(
+	name string,
+	params []*ast.Parameter,
+	outer *object.Environment,
+	args []ast.Expression,
+	env *object.Environment,
+) (*object.Environment, object.Object) {
+	if len(args) != len(params) {
+		return nil, newError("wrong number of arguments to %s: want=%d, got=%d",
+			name, len(params), len(args))
+	}
+
+	frame := object.NewEnclosedEnvironment(outer)
+
+	for i, param := range params {
+		if param.ByRef {
+			ident, ok := args[i].(*ast.Identifier)
+			if !ok {
+				return nil, newError("var parameter %s of %s needs a variable, got %s",
+					param.Name.Value, name, args[i].String())
+			}
+
+			if _, ok := env.Get(ident.Value); !ok {
+				return nil, newError("identifier not found: %s", ident.Value)
+			}
+
+			frame.Set(param.Name.Value, &object.Reference{Env: env, Name: ident.Value})
+			continue
+		}
+
+		val := Eval(args[i], env)
+		if isError(val) {
+			return nil, val
+		}
+		if param.Type.Value == "real" {
+			val = toReal(val)
+		}
+		frame.Set(param.Name.Value, copyValue(val))
+	}
+
+	return frame, nil
+}
@@ -0,0 +1,88 @@
+package evaluator
+
+import (
+	"bufio"
+	"interp/object"
+	"io"
+	"unicode/utf8"
+)
+
+func evalCharInfixExpression(
+	operator string,
+	left, right object.Object,
+) object.Object {
+	leftVal := left.(*object.Char).Value
+	rightVal := right.(*object.Char).Value
+
+	switch operator {
+	case "<":
+		return nativeCmp(leftVal < rightVal)
+	case ">":
+		return nativeCmp(leftVal > rightVal)
+	case "<=":
+		return nativeCmp(leftVal <= rightVal)
+	case ">=":
+		return nativeCmp(leftVal >= rightVal)
+	case "=":
+		return nativeCmp(leftVal == rightVal)
+	case "<>":
+		return nativeCmp(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+// isText reports whether + joins obj with a string: strings and characters
+// can be concatenated in any mix as long as one side is a string.
+func isText(obj object.Object) bool {
+	return obj.Type() == object.STRING_OBJ || obj.Type() == object.CHAR_OBJ
+}
+
+func builtinOrd(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("ord", args, 1); err != nil {
+		return err
+	}
+
+	arg, ok := args[0].(*object.Char)
+	if !ok {
+		return newError("argument to ord must be CHAR, got %s", args[0].Type())
+	}
+
+	return fitInteger(rt, int64(arg.Value), false,
+		"ord of %q out of range for %d-bit integers", arg.Value, rt.WordSize)
+}
+
+func builtinChr(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("chr", args, 1); err != nil {
+		return err
+	}
+
+	arg, ok := args[0].(*object.Integer)
+	if !ok {
+		return newError("argument to chr must be INTEGER, got %s", args[0].Type())
+	}
+	if arg.Big != nil || arg.Value < 0 || arg.Value > utf8.MaxRune || !utf8.ValidRune(rune(arg.Value)) {
+		return newError("chr of %s is not a character", arg.Inspect())
+	}
+
+	return &object.Char{Value: rune(arg.Value)}
+}
+
+type runeReader interface {
+	io.Reader
+	io.RuneScanner
+}
+
+// input returns the runtime's input as a rune scanner. A reader without
+// UnreadRune is buffered once and replaced, so that scanning a number does
+// not swallow the character after it and read c sees exactly what is left.
+func input(rt *object.Runtime) runeReader {
+	if rr, ok := rt.In.(runeReader); ok {
+		return rr
+	}
+
+	br := bufio.NewReader(rt.In)
+	rt.In = br
+	return br
+}
@@ -1,13 +1,20 @@
 package evaluator
 
 import (
+	"errors"
 	"fmt"
 	"interp/ast"
 	"interp/object"
+	"interp/token"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
 )
 
 var (
 	NULL  = &object.Null{}
+	EXIT  = &object.Exit{}
 	TRUE  = &object.Integer{Value: 1}
 	FALSE = &object.Integer{Value: 0}
 )
@@ -19,32 +26,120 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return evalProgram(node, env)
 
 	case *ast.ExpressionStatement:
+		// a procedure without parameters may be called by its name alone
+		if ident, ok := node.Expression.(*ast.Identifier); ok {
+			if proc, ok := lookupIdentifier(ident, env).(*object.Procedure); ok {
+				return applyProcedure(proc, nil, env)
+			}
+		}
 		return Eval(node.Expression, env)
 
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return evalIntegerLiteral(node, env.Runtime())
+
+	case *ast.FloatLiteral:
+		return &object.Real{Value: node.Value}
+
+	case *ast.StringLiteral:
+		return &object.String{Value: node.Value}
+
+	case *ast.CharLiteral:
+		return &object.Char{Value: node.Value}
 
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 
 	case *ast.DeclStatment:
-		env.Set(node.Name.Value, &object.Integer{Value: 0})
+		val := newValue(node.Type.Value, env)
+		if isError(val) {
+			return val
+		}
+		env.Set(node.Name.Value, val)
 
-	case *ast.AssignStatement:
+	case *ast.DeclStatmentVector:
+		return evalVectorDeclaration(node, env)
+
+	case *ast.ConstStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
 		env.Set(node.Name.Value, val)
 
+	case *ast.DeclGroupStatement:
+		for _, decl := range node.Decls {
+			if val := Eval(decl, env); isError(val) {
+				return val
+			}
+		}
+
+	case *ast.TypeStatement:
+		env.Set(node.Name.Value, &object.RecordType{
+			Name:   node.Name.Value,
+			Fields: node.Record.Fields,
+			Env:    env,
+		})
+
+	case *ast.AssignStatement:
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if node.Target != nil {
+			if err := assignTarget(node.Target, val, env); err != nil {
+				return err
+			}
+			break
+		}
+		if err := assignVariable(node, val, env); err != nil {
+			return err
+		}
+
+	case *ast.ProcedureStatement:
+		env.Set(node.Name.Value, &object.Procedure{
+			Name:       node.Name.Value,
+			Parameters: node.Parameters,
+			Body:       node.Body,
+			Env:        env,
+		})
+
+	case *ast.FunctionStatement:
+		env.Set(node.Name.Value, &object.Function{
+			Name:       node.Name.Value,
+			Parameters: node.Parameters,
+			ReturnType: node.ReturnType.Value,
+			Body:       node.Body,
+			Env:        env,
+		})
+
+	case *ast.CallStatement:
+		return evalCallStatement(node, env)
+
+	case *ast.CallExpression:
+		return evalCallExpression(node, env)
+
+	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &object.ReturnValue{Value: NULL}
+		}
+		val := Eval(node.ReturnValue, env)
+		if isError(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, right, env.Runtime())
 
 	case *ast.InfixExpression:
+		if node.Operator == "and" || node.Operator == "or" {
+			return evalLogicalExpression(node, env)
+		}
+
 		left := Eval(node.Left, env)
 		if isError(left) {
 			return left
@@ -55,25 +150,55 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			return right
 		}
 
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right, env.Runtime())
 
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 
+	case *ast.CaseExpression:
+		return evalCaseExpression(node, env)
+
 	case *ast.BeginExpression:
 		return evalBeginExpression(node, env)
 
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 
+	case *ast.IndexExpression:
+		return evalIndexExpression(node, env)
+
+	case *ast.FieldExpression:
+		return evalFieldExpression(node, env)
+
+	case *ast.LabeledStatement:
+		return Eval(node.Statement, env)
+
 	case *ast.GotoStatement:
-		return &object.Goto{Mark: node.Token.Literal}
+		return &object.Goto{Mark: node.Name.Value}
 
 	case *ast.LoopExpression:
 		return evalLoopExpression(node, env)
 
+	case *ast.WhileExpression:
+		return evalWhileExpression(node, env)
+
+	case *ast.RepeatExpression:
+		return evalRepeatExpression(node, env)
+
+	case *ast.ForExpression:
+		return evalForExpression(node, env)
+
+	case *ast.AssertStatement:
+		return evalAssertStatement(node, env)
+
+	case *ast.ExitStatement:
+		return evalExitStatement(node, env)
+
 	case *ast.ReadExpression:
 		return evalReadExpression(node, env)
+
+	case *ast.WriteExpression:
+		return evalWriteExpression(node, env)
 	}
 	return NULL
 }
@@ -82,33 +207,369 @@ func evalReadExpression(
 	node *ast.ReadExpression,
 	env *object.Environment,
 ) object.Object {
-	for _, key := range node.Arguments {
-		var val int64
-		fmt.Scan(&val)
-		env.Set(key.TokenLiteral(), &object.Integer{Value: val})
+	rt := env.Runtime()
+	file, args := fileArguments(node.Arguments, env)
+	in, err := fileInput(node, file, rt)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range args {
+		var current object.Object
+		if ident, ok := key.(*ast.Identifier); ok {
+			current, _ = env.Get(ident.Value)
+		} else {
+			current = Eval(key, env)
+			if isError(current) {
+				return current
+			}
+		}
+
+		switch current := current.(type) {
+		case *object.Record:
+			return newErrorAt(node.Token, "cannot read %s of type %s", key.String(), current.TypeName)
+		case *object.File:
+			return newErrorAt(node.Token, "cannot read %s of type file", key.String())
+		}
+
+		// a whole vector is read element by element in row-major order
+		if vector, ok := current.(*object.Vector); ok {
+			for i, element := range vector.Elements {
+				switch element := element.(type) {
+				case *object.Record:
+					return newErrorAt(node.Token, "cannot read %s with elements of type %s", key.String(), element.TypeName)
+				case *object.File:
+					return newErrorAt(node.Token, "cannot read %s with elements of type file", key.String())
+				}
+				val := readValue(node, key, rt, in, element)
+				if isError(val) {
+					return val
+				}
+				vector.Elements[i] = val
+			}
+			continue
+		}
+
+		val := readValue(node, key, rt, in, current)
+		if isError(val) {
+			return val
+		}
+		if err := assignTarget(key, val, env); err != nil {
+			return err
+		}
+	}
+	return NULL
+}
+
+// readValue scans one value of the same type as current into target.
+// Strings and numbers are read as whitespace-delimited words, a character
+// is the next one in the input whatever it is. Running out of input or a
+// word that is not a number of the right type is an error.
+func readValue(
+	node *ast.ReadExpression,
+	target ast.Expression,
+	rt *object.Runtime,
+	in runeReader,
+	current object.Object,
+) object.Object {
+	if _, ok := current.(*object.Char); ok {
+		r, _, err := in.ReadRune()
+		if err != nil {
+			return newErrorAt(node.Token, "unexpected end of input reading %s", target.String())
+		}
+		return &object.Char{Value: r}
+	}
+
+	var word string
+	if _, err := fmt.Fscan(in, &word); err != nil {
+		return newErrorAt(node.Token, "unexpected end of input reading %s", target.String())
+	}
+
+	switch current.(type) {
+	case *object.String:
+		return &object.String{Value: word}
+	case *object.Real:
+		x, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			return newErrorAt(node.Token, "cannot read %q into %s: not a real number", word, target.String())
+		}
+		return &object.Real{Value: x}
+	}
+
+	if rt.Arithmetic == object.ARITH_BIG {
+		val, ok := new(big.Int).SetString(word, 10)
+		if !ok {
+			return newErrorAt(node.Token, "cannot read %q into %s: not an integer", word, target.String())
+		}
+		return object.NewBigInteger(val)
+	}
+
+	val, err := strconv.ParseInt(word, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return newErrorAt(node.Token, "input %s out of range for %d-bit integers", word, rt.WordSize)
+	}
+	if err != nil {
+		return newErrorAt(node.Token, "cannot read %q into %s: not an integer", word, target.String())
+	}
+
+	result := fitInteger(rt, val, false,
+		"input %d out of range for %d-bit integers", val, rt.WordSize)
+	if errObj, ok := result.(*object.Error); ok {
+		return newErrorAt(node.Token, "%s", errObj.Message)
+	}
+	return result
+}
+
+var writeSpecifiers = map[string]string{
+	"skip":  "\n",
+	"space": " ",
+	"tab":   "\t",
+}
+
+func evalWriteExpression(
+	node *ast.WriteExpression,
+	env *object.Environment,
+) object.Object {
+	file, args := fileArguments(node.Arguments, env)
+	out, err := fileOutput(node, file, env.Runtime())
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		if spec, ok := arg.(*ast.WriteSpecifier); ok {
+			io.WriteString(out, writeSpecifiers[spec.Token.Literal])
+			continue
+		}
+		if item, ok := arg.(*ast.WriteItem); ok {
+			text, err := formatWriteItem(item, env)
+			if err != nil {
+				return err
+			}
+			io.WriteString(out, text)
+			continue
+		}
+
+		val := Eval(arg, env)
+		if isError(val) {
+			return val
+		}
+		switch val := val.(type) {
+		case *object.Vector:
+			writeVector(out, val)
+			continue
+		case *object.File:
+			return newErrorAt(node.Token, "cannot write %s of type file", arg.String())
+		}
+		io.WriteString(out, val.Inspect())
 	}
 	return NULL
 }
 
+// zeroValue is the value a variable of the given type starts with.
+func zeroValue(typ string) object.Object {
+	switch typ {
+	case "real":
+		return &object.Real{Value: 0}
+	case "string":
+		return &object.String{Value: ""}
+	case "char":
+		return &object.Char{Value: 0}
+	case "file":
+		return &object.File{}
+	default:
+		return &object.Integer{Value: 0}
+	}
+}
+
+func evalIntegerLiteral(
+	node *ast.IntegerLiteral,
+	rt *object.Runtime,
+) object.Object {
+	if node.Big != nil {
+		if rt.Arithmetic == object.ARITH_BIG {
+			return object.NewBigInteger(node.Big)
+		}
+		return newError("integer literal %s out of range for %d-bit integers",
+			node.Token.Literal, rt.WordSize)
+	}
+
+	return fitInteger(rt, node.Value, false,
+		"integer literal %s out of range for %d-bit integers",
+		node.Token.Literal, rt.WordSize)
+}
+
 func evalLoopExpression(
 	node *ast.LoopExpression,
 	env *object.Environment,
 ) object.Object {
-	var result object.Object = NULL
+	for {
+		result := Eval(node.Body, env)
+
+		if res, stop := loopInterrupted(result); stop {
+			return res
+		}
+	}
+}
 
+func evalWhileExpression(
+	node *ast.WhileExpression,
+	env *object.Environment,
+) object.Object {
 	for {
-		result = Eval(node.Body, env)
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
 
-		if result != nil {
-			rt := result.Type()
-			if rt == object.ERROR_OBJ || rt == object.GOTO_OBJ {
-				return result
-			}
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		result := Eval(node.Body, env)
+
+		if res, stop := loopInterrupted(result); stop {
+			return res
+		}
+	}
+}
+
+func evalRepeatExpression(
+	node *ast.RepeatExpression,
+	env *object.Environment,
+) object.Object {
+	for {
+		result := Eval(node.Body, env)
+
+		if res, stop := loopInterrupted(result); stop {
+			return res
+		}
+
+		condition := Eval(node.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return NULL
+		}
+	}
+}
 
+func evalForExpression(
+	node *ast.ForExpression,
+	env *object.Environment,
+) object.Object {
+	from, ok := evalForBound(node.From, env)
+	if !ok {
+		return from
+	}
+
+	to, ok := evalForBound(node.To, env)
+	if !ok {
+		return to
+	}
+
+	first := from.(*object.Integer).Value
+	last := to.(*object.Integer).Value
+	step := int64(1)
+	if node.Downto {
+		step = -1
+	}
+
+	if node.Downto && first < last || !node.Downto && first > last {
+		return NULL
+	}
+
+	for i := first; ; i += step {
+		env.Assign(node.Variable.Value, &object.Integer{Value: i})
+
+		result := Eval(node.Body, env)
+
+		if res, stop := loopInterrupted(result); stop {
+			return res
 		}
 
+		if i == last {
+			return NULL
+		}
+	}
+}
+
+// evalForBound evaluates a for loop bound, returning the error object and
+// false when it is not an integer usable as a loop counter.
+func evalForBound(
+	node ast.Expression,
+	env *object.Environment,
+) (object.Object, bool) {
+	bound := Eval(node, env)
+	if isError(bound) {
+		return bound, false
+	}
+
+	integer, ok := bound.(*object.Integer)
+	if !ok {
+		return newError("for loop bound must be %s, got %s",
+			object.INTEGER_OBJ, bound.Type()), false
+	}
+
+	if integer.Big != nil {
+		return newError("for loop bound %s out of range", integer.Inspect()), false
+	}
+
+	return integer, true
+}
+
+// loopInterrupted reports whether the result of a loop body ends the loop,
+// and if so what the loop evaluates to: errors, jumps and returns
+// propagate, exit is consumed by the loop.
+func loopInterrupted(result object.Object) (object.Object, bool) {
+	if result == nil {
+		return nil, false
+	}
+
+	switch result.Type() {
+	case object.ERROR_OBJ, object.HALT_OBJ, object.GOTO_OBJ, object.RETURN_VALUE_OBJ:
+		return result, true
+	case object.EXIT_OBJ:
+		return NULL, true
+	}
+
+	return nil, false
+}
+
+func evalExitStatement(
+	node *ast.ExitStatement,
+	env *object.Environment,
+) object.Object {
+	if node.Condition == nil {
+		return EXIT
+	}
+
+	condition := Eval(node.Condition, env)
+	if isError(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return EXIT
+	}
+	return NULL
+}
+
+func evalAssertStatement(
+	node *ast.AssertStatement,
+	env *object.Environment,
+) object.Object {
+	condition := Eval(node.Condition, env)
+	if isError(condition) {
+		return condition
 	}
 
+	if !isTruthy(condition) {
+		return newErrorAt(node.Token, "assertion failed: %s", node.Condition.String())
+	}
+	return NULL
 }
 
 func evalBlockStatement(
@@ -116,34 +577,35 @@ func evalBlockStatement(
 	env *object.Environment,
 ) object.Object {
 	var result object.Object = NULL
-	newEnv := object.NewEnclosedEnvironment(env)
 
+	labels := make(map[string]int)
 	for line, st := range block.Statements {
-		res, ok := st.(*ast.MarkerStatement)
-		if ok {
-			newEnv.Marks[res.Marker.Value] = line + 1
+		for {
+			labeled, ok := st.(*ast.LabeledStatement)
+			if !ok {
+				break
+			}
+			labels[labeled.Label.Value] = line
+			st = labeled.Statement
 		}
 	}
 
 	for line := 0; line < len(block.Statements); line++ {
-		if _, ok := block.Statements[line].(*ast.MarkerStatement); ok {
-			continue
-		}
-
 		result = Eval(block.Statements[line], env)
 
 		if result != nil {
 			rt := result.Type()
-			if rt == object.ERROR_OBJ {
+			if rt == object.ERROR_OBJ || rt == object.HALT_OBJ || rt == object.EXIT_OBJ || rt == object.RETURN_VALUE_OBJ {
 				return result
 			}
 
 			if rt == object.GOTO_OBJ {
-				ml, ok := newEnv.Marks[result.Inspect()]
+				target, ok := labels[result.(*object.Goto).Mark]
 				if !ok {
 					return result
 				}
-				line = ml
+				line = target - 1
+				result = NULL
 			}
 		}
 	}
@@ -151,17 +613,37 @@ func evalBlockStatement(
 	return result
 }
 
+// evalIdentifier evaluates a name. A function or builtin named without an
+// argument list is called, as Pascal does for parameterless routines; a
+// procedure gives no value.
 func evalIdentifier(
 	node *ast.Identifier,
 	env *object.Environment,
+) object.Object {
+	switch val := lookupIdentifier(node, env).(type) {
+	case *object.Procedure:
+		return newErrorAt(node.Token, "procedure %s does not return a value", val.Name)
+	case *object.Function:
+		return applyFunction(val, nil, env)
+	case *object.Builtin:
+		return applyBuiltin(val, nil, env, node.Token)
+	default:
+		return val
+	}
+}
+
+// lookupIdentifier returns what a name refers to without calling it.
+func lookupIdentifier(
+	node *ast.Identifier,
+	env *object.Environment,
 ) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
 	}
 
-	// if builtin, ok := builtins[node.Value]; ok {
-	// 	return builtin
-	// }
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
 
 	return newError("%s", "identifier not found: "+node.Value)
 }
@@ -191,19 +673,79 @@ func evalIfExpression(
 	}
 }
 
+func evalCaseExpression(
+	ce *ast.CaseExpression,
+	env *object.Environment,
+) object.Object {
+	subject := Eval(ce.Subject, env)
+	if isError(subject) {
+		return subject
+	}
+
+	if subject.Type() != object.INTEGER_OBJ {
+		return newError("case expression must be INTEGER, got %s", subject.Type())
+	}
+
+	for _, arm := range ce.Arms {
+		for _, label := range arm.Labels {
+			value := Eval(label, env)
+			if isError(value) {
+				return value
+			}
+
+			if isTruthy(evalInfixExpression("=", subject, value, env.Runtime())) {
+				return Eval(arm.Body, env)
+			}
+		}
+	}
+
+	if ce.Alternative != nil {
+		return Eval(ce.Alternative, env)
+	}
+	return NULL
+}
+
+// isTruthy follows the language's convention that conditions are integers
+// and only zero is false.
 func isTruthy(obj object.Object) bool {
-	switch obj {
-	case NULL:
-		return false
-	case TRUE:
-		return true
-	case FALSE:
+	switch obj := obj.(type) {
+	case *object.Null:
 		return false
+	case *object.Integer:
+		return obj.Big != nil || obj.Value != 0
+	case *object.Real:
+		return obj.Value != 0
 	default:
 		return true
 	}
 }
 
+// evalLogicalExpression evaluates and/or, skipping the right operand when
+// the left one already decides the result.
+func evalLogicalExpression(
+	node *ast.InfixExpression,
+	env *object.Environment,
+) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	if node.Operator == "and" && !isTruthy(left) {
+		return FALSE
+	}
+	if node.Operator == "or" && isTruthy(left) {
+		return TRUE
+	}
+
+	right := Eval(node.Right, env)
+	if isError(right) {
+		return right
+	}
+
+	return nativeCmp(isTruthy(right))
+}
+
 func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 
 	block := &ast.BlockStatement{Statements: program.Statements}
@@ -216,20 +758,36 @@ func evalProgram(program *ast.Program, env *object.Environment) object.Object {
 	// 	}
 	// }
 
-	return Eval(block, env)
+	result := Eval(block, env)
+	switch result := result.(type) {
+	case *object.Goto:
+		return newError("label not found: %s", result.Mark)
+	case *object.ReturnValue:
+		return result.Value
+	}
+
+	return result
 }
 
+// isError reports whether obj stops the evaluation: a runtime error, or a
+// halt, which unwinds the same way.
 func isError(obj object.Object) bool {
 	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+		return obj.Type() == object.ERROR_OBJ || obj.Type() == object.HALT_OBJ
 	}
 	return false
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
+func evalPrefixExpression(
+	operator string,
+	right object.Object,
+	rt *object.Runtime,
+) object.Object {
 	switch operator {
 	case "-":
-		return evalMinusPrefixOperatorExpression(right)
+		return evalMinusPrefixOperatorExpression(right, rt)
+	case "not":
+		return nativeCmp(!isTruthy(right))
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -238,10 +796,27 @@ func evalPrefixExpression(operator string, right object.Object) object.Object {
 func evalInfixExpression(
 	operator string,
 	left, right object.Object,
+	rt *object.Runtime,
 ) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right)
+		return evalIntegerInfixExpression(operator, left, right, rt)
+
+	case left.Type() == object.REAL_OBJ && right.Type() == object.REAL_OBJ:
+		return evalRealInfixExpression(operator, left, right)
+
+	case left.Type() == object.INTEGER_OBJ && right.Type() == object.REAL_OBJ,
+		left.Type() == object.REAL_OBJ && right.Type() == object.INTEGER_OBJ:
+		return evalRealInfixExpression(operator, toReal(left), toReal(right))
+
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return evalStringInfixExpression(operator, left, right)
+
+	case left.Type() == object.CHAR_OBJ && right.Type() == object.CHAR_OBJ:
+		return evalCharInfixExpression(operator, left, right)
+
+	case operator == "+" && isText(left) && isText(right):
+		return &object.String{Value: left.Inspect() + right.Inspect()}
 
 	case left.Type() != right.Type():
 		return newError("type mismatch: %s %s %s",
@@ -252,26 +827,69 @@ func evalInfixExpression(
 	}
 }
 
-func evalIntegerInfixExpression(
+func evalStringInfixExpression(
 	operator string,
 	left, right object.Object,
 ) object.Object {
-	leftVal := left.(*object.Integer).Value
-	rightVal := right.(*object.Integer).Value
+	leftVal := left.(*object.String).Value
+	rightVal := right.(*object.String).Value
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
-	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
-	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
-	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		return &object.String{Value: leftVal + rightVal}
 	case "<":
 		return nativeCmp(leftVal < rightVal)
 	case ">":
 		return nativeCmp(leftVal > rightVal)
+	case "<=":
+		return nativeCmp(leftVal <= rightVal)
+	case ">=":
+		return nativeCmp(leftVal >= rightVal)
+	case "=":
+		return nativeCmp(leftVal == rightVal)
+	case "<>":
+		return nativeCmp(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+func evalIntegerInfixExpression(
+	operator string,
+	left, right object.Object,
+	rt *object.Runtime,
+) object.Object {
+	if operator == "/" && !rt.IntegerDivide {
+		return evalRealInfixExpression(operator, toReal(left), toReal(right))
+	}
+
+	leftInt := left.(*object.Integer)
+	rightInt := right.(*object.Integer)
+
+	if leftInt.Big != nil || rightInt.Big != nil {
+		switch operator {
+		case "+", "-", "*", "/", "div":
+			return evalBigArithmetic(operator, leftInt.BigValue(), rightInt.BigValue())
+		default:
+			return evalBigComparison(operator, leftInt.BigValue(), rightInt.BigValue())
+		}
+	}
+
+	leftVal := leftInt.Value
+	rightVal := rightInt.Value
+
+	switch operator {
+	case "+", "-", "*", "/", "div":
+		return evalIntegerArithmetic(operator, leftVal, rightVal, rt)
+	case "<":
+		return nativeCmp(leftVal < rightVal)
+	case ">":
+		return nativeCmp(leftVal > rightVal)
+	case "<=":
+		return nativeCmp(leftVal <= rightVal)
+	case ">=":
+		return nativeCmp(leftVal >= rightVal)
 	case "=":
 		return nativeCmp(leftVal == rightVal)
 	case "<>":
@@ -289,15 +907,33 @@ func nativeCmp(input bool) *object.Integer {
 	return FALSE
 }
 
-func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
+func evalMinusPrefixOperatorExpression(
+	right object.Object,
+	rt *object.Runtime,
+) object.Object {
+	if real, ok := right.(*object.Real); ok {
+		return &object.Real{Value: -real.Value}
+	}
+
 	if right.Type() != object.INTEGER_OBJ {
 		return newError("unknown operator: -%s", right.Type())
 	}
 
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	integer := right.(*object.Integer)
+	if integer.Big != nil || integer.Value == math.MinInt64 && rt.Arithmetic == object.ARITH_BIG {
+		return object.NewBigInteger(new(big.Int).Neg(integer.BigValue()))
+	}
+
+	value := integer.Value
+	return fitInteger(rt, -value, value == math.MinInt64,
+		"integer overflow: -(%d)", value)
 }
 
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
+
+// newErrorAt prefixes the message with the position of tok.
+func newErrorAt(tok token.Token, format string, a ...interface{}) *object.Error {
+	return newError("line %d, column %d: %s", tok.Line, tok.Column, fmt.Sprintf(format, a...))
+}
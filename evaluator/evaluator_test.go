@@ -1,9 +1,12 @@
 package evaluator
 
 import (
+	"bytes"
 	"interp/lexer"
 	"interp/object"
 	"interp/parser"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -48,6 +51,20 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"1 <> 1;", 0},
 		{"1 = 2;", 0},
 		{"1 <> 2;", 1},
+		{"1 <= 1;", 1},
+		{"2 <= 1;", 0},
+		{"1 >= 2;", 0},
+		{"2 >= 2;", 1},
+		{"1 < 2 and 2 < 3;", 1},
+		{"1 < 2 and 3 < 2;", 0},
+		{"1 > 2 or 2 < 3;", 1},
+		{"1 > 2 or 3 < 2;", 0},
+		{"not 1;", 0},
+		{"not 0;", 1},
+		{"not (2 - 2);", 1},
+		{"not 1 = 2;", 1},
+		{"5 and 7;", 1},
+		{"0 or 3 - 3;", 0},
 	}
 
 	for _, tt := range tests {
@@ -66,6 +83,9 @@ func TestIfElseExpressions(t *testing.T) {
 		{"if 1 > 2 then 10; end;", nil},
 		{"if 1 > 2 then 10; else 20; end;", 20},
 		{"if 1 < 2 then 10; else 20; end;", 10},
+		{"if 1 - 1 then 10; else 20; end;", 20},
+		{"if 3 - 2 then 10; else 20; end;", 10},
+		{"if 0 then 10; end;", nil},
 	}
 
 	for _, tt := range tests {
@@ -79,6 +99,29 @@ func TestIfElseExpressions(t *testing.T) {
 	}
 }
 
+func TestLogicalShortCircuit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"a: integer; a = 0 or 10 / a > 1;", int64(1)},
+		{"a: integer; a <> 0 and 10 / a > 1;", int64(0)},
+		{"a: integer; a = 0 and 10 / a > 1;", "division by zero: 10 / 0"},
+		{"a: integer; a <> 0 or 10 / a > 1;", "division by zero: 10 / 0"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int64:
+			testCompObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
 func TestBeginExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -110,6 +153,7 @@ func TestLetStatements(t *testing.T) {
 		{"a: integer; a := 5 * 5; a;", 25},
 		{"a: integer; a := 5; b: integer; b := a; b;", 5},
 		{"a: integer; a := 5; b: integer; b := a; c: integer; c := a + b + 5; c;", 15},
+		{"a, b, c: integer; a := 5; b := a; c := a + b + 5; c;", 15},
 	}
 
 	for _, tt := range tests {
@@ -207,6 +251,161 @@ func TestLoopExpression(t *testing.T) {
 	}
 }
 
+func TestExitStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]int64
+	}{
+		{
+			input: `a: integer;
+			b: integer;
+			loop begin
+				a := a + 1;
+				if a > 3 then
+					exit;
+				end;
+			end;
+			b := a * 2;`,
+			expected: map[string]int64{
+				"a": 4,
+				"b": 8,
+			},
+		},
+		{
+			input: `a: integer;
+			b: integer;
+			loop begin
+				exit when a = 7;
+				a := a + 1;
+				loop begin
+					b := b + 1;
+					exit when b > 100;
+				end;
+			end;`,
+			expected: map[string]int64{
+				"a": 7,
+				"b": 107,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		env := object.NewEnvironment()
+		Eval(program, env)
+
+		for key, val := range tt.expected {
+			env_var, ok := env.Get(key)
+			if !ok {
+				t.Fatalf("variable %s not exist", key)
+			}
+
+			integer_obj, ok := env_var.(*object.Integer)
+			if !ok {
+				t.Fatalf("env_var is not integer go %T", env_var)
+			}
+
+			if integer_obj.Value != val {
+				t.Fatalf("value of %s is not %d, got %d", key, val, integer_obj.Value)
+			}
+		}
+	}
+}
+
+func TestLoopForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]int64
+	}{
+		{
+			input: `i: integer; s: integer;
+			while i < 5 do
+				i := i + 1;
+				s := s + i;
+			end;`,
+			expected: map[string]int64{"i": 5, "s": 15},
+		},
+		{
+			input: `i: integer;
+			while i > 0 do
+				i := 100;
+			end;`,
+			expected: map[string]int64{"i": 0},
+		},
+		{
+			input: `i: integer;
+			repeat
+				i := i + 2;
+			until i > 5;`,
+			expected: map[string]int64{"i": 6},
+		},
+		{
+			input: `i: integer; s: integer;
+			for i := 1 to 10 do
+				s := s + i;
+			end;`,
+			expected: map[string]int64{"i": 10, "s": 55},
+		},
+		{
+			input: `i: integer; s: integer;
+			for i := 3 downto 1 do
+				s := s * 10 + i;
+			end;`,
+			expected: map[string]int64{"i": 1, "s": 321},
+		},
+		{
+			input: `i: integer; s: integer;
+			s := 7;
+			for i := 5 to 1 do
+				s := 0;
+			end;`,
+			expected: map[string]int64{"i": 0, "s": 7},
+		},
+		{
+			input: `i: integer; s: integer;
+			for i := 1 to 100 do
+				exit when i = 4;
+				s := s + i;
+			end;`,
+			expected: map[string]int64{"i": 4, "s": 6},
+		},
+		{
+			input: `i: integer;
+			for i := 9223372036854775806 to 9223372036854775807 do end;`,
+			expected: map[string]int64{"i": 9223372036854775807},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors: %v", p.Errors())
+		}
+		env := object.NewEnvironment()
+		Eval(program, env)
+
+		for key, val := range tt.expected {
+			env_var, ok := env.Get(key)
+			if !ok {
+				t.Fatalf("variable %s not exist", key)
+			}
+
+			integer_obj, ok := env_var.(*object.Integer)
+			if !ok {
+				t.Fatalf("env_var is not integer go %T", env_var)
+			}
+
+			if integer_obj.Value != val {
+				t.Fatalf("value of %s is not %d, got %d", key, val, integer_obj.Value)
+			}
+		}
+	}
+}
+
 func TestGoto(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -271,6 +470,269 @@ func TestGoto(t *testing.T) {
 	}
 }
 
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		mode     object.ArithmeticMode
+		wordSize int
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1;", object.ARITH_WRAP, 64, int64(-9223372036854775808)},
+		{"9223372036854775807 + 1;", object.ARITH_CHECKED, 64, "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2;", object.ARITH_CHECKED, 64, "integer overflow: -9223372036854775807 - 2"},
+		{"4611686018427387904 * 2;", object.ARITH_CHECKED, 64, "integer overflow: 4611686018427387904 * 2"},
+		{"a: integer; a := -9223372036854775807 - 1; -a;", object.ARITH_CHECKED, 64, "integer overflow: -(-9223372036854775808)"},
+		{"32767 + 1;", object.ARITH_WRAP, 16, int64(-32768)},
+		{"32767 + 1;", object.ARITH_CHECKED, 16, "integer overflow: 32767 + 1"},
+		{"200 * 200;", object.ARITH_WRAP, 16, int64(-25536)},
+		{"200 * 200;", object.ARITH_CHECKED, 32, int64(40000)},
+		{"70000;", object.ARITH_WRAP, 16, int64(4464)},
+		{"70000;", object.ARITH_CHECKED, 16, "integer literal 70000 out of range for 16-bit integers"},
+		{"2147483647 + 1;", object.ARITH_CHECKED, 32, "integer overflow: 2147483647 + 1"},
+		{"5 / 0;", object.ARITH_WRAP, 64, "division by zero: 5 / 0"},
+	}
+
+	for _, tt := range tests {
+		rt := newTestRuntime()
+		rt.Arithmetic = tt.mode
+		rt.WordSize = tt.wordSize
+		evaluated := testEvalWithRuntime(tt.input, rt)
+
+		switch expected := tt.expected.(type) {
+		case int64:
+			testIntegerObject(t, evaluated, expected)
+		case string:
+			testErrorObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestBigIntegers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1;", "9223372036854775808"},
+		{"-9223372036854775807 - 1 - 1;", "-9223372036854775809"},
+		{"4294967296 * 4294967296 * 4294967296;", "79228162514264337593543950336"},
+		{"99999999999999999999 / 3;", "33333333333333333333"},
+		{"-(-9223372036854775807 - 1);", "9223372036854775808"},
+		{"99999999999999999999 > 1;", "1"},
+		{"99999999999999999999 = 99999999999999999999;", "1"},
+		{"1 <> 99999999999999999999;", "1"},
+		{"11111111111111111111111111111111111111111111111111111111111111111111B;", "295147905179352825855"},
+		{"1FFFFFFFFFFFFFFFFH - 1FFFFFFFFFFFFFFFFH;", "0"},
+		{"99999999999999999999 / 0;", "ERROR: division by zero: 99999999999999999999 / 0"},
+	}
+
+	for _, tt := range tests {
+		rt := newTestRuntime()
+		rt.Arithmetic = object.ARITH_BIG
+		testInspect(t, tt.input, testEvalWithRuntime(tt.input, rt), tt.expected)
+	}
+
+	rt := newTestRuntime()
+	rt.Arithmetic = object.ARITH_BIG
+	evaluated := testEvalWithRuntime("(9223372036854775807 + 1) - 1;", rt)
+	integer, ok := evaluated.(*object.Integer)
+	if !ok || integer.Big != nil || integer.Value != 9223372036854775807 {
+		t.Errorf("result did not return to int64 representation. got=%+v", evaluated)
+	}
+
+	evaluated = testEval("99999999999999999999;")
+	testErrorObject(t, evaluated, "integer literal 99999999999999999999 out of range for 64-bit integers")
+}
+
+func TestLabeledStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]int64
+	}{
+		{
+			input: `a, b: integer;
+			again: a := a + 1;
+			if a < 5 then
+				goto again;
+			end;
+			b := a * 10;`,
+			expected: map[string]int64{"a": 5, "b": 50},
+		},
+		{
+			input: `a, b: integer;
+			begin
+				a := 1;
+				goto over;
+				a := 2;
+				over: b := a + 1;
+			end;`,
+			expected: map[string]int64{"a": 1, "b": 2},
+		},
+		{
+			input: `a: integer;
+			begin
+				loop begin
+					a := a + 1;
+					if a = 3 then
+						goto fin;
+					end;
+				end;
+				a := 100;
+				fin:
+			end;`,
+			expected: map[string]int64{"a": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors: %v", p.Errors())
+		}
+		env := object.NewEnvironment()
+		Eval(program, env)
+
+		for key, val := range tt.expected {
+			env_var, ok := env.Get(key)
+			if !ok {
+				t.Fatalf("variable %s not exist", key)
+			}
+
+			integer_obj, ok := env_var.(*object.Integer)
+			if !ok {
+				t.Fatalf("env_var is not integer go %T", env_var)
+			}
+
+			if integer_obj.Value != val {
+				t.Fatalf("value of %s is not %d, got %d", key, val, integer_obj.Value)
+			}
+		}
+	}
+
+	testErrorObject(t, testEval("goto nowhere;"), "label not found: nowhere")
+	testErrorObject(t, testEval("begin inner: ; end; goto inner;"), "label not found: inner")
+}
+
+func TestProcedures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]int64
+	}{
+		{
+			input: `a, b: integer;
+			procedure swap(var x, y: integer);
+			begin
+				t: integer;
+				t := x;
+				x := y;
+				y := t;
+			end;
+			a := 1;
+			b := 2;
+			swap(a, b);`,
+			expected: map[string]int64{"a": 2, "b": 1},
+		},
+		{
+			input: `a, t: integer;
+			procedure inc(x: integer);
+			begin
+				t: integer;
+				t := 100;
+				x := x + 1;
+				a := x;
+			end;
+			t := 7;
+			a := 1;
+			inc(a + 10);`,
+			expected: map[string]int64{"a": 12, "t": 7},
+		},
+		{
+			input: `r: integer;
+			procedure fact(n: integer; var acc: integer);
+			begin
+				if n > 1 then
+					acc := acc * n;
+					fact(n - 1, acc);
+				end;
+			end;
+			r := 1;
+			fact(10, r);`,
+			expected: map[string]int64{"r": 3628800},
+		},
+		{
+			input: `r: integer;
+			procedure add(var sum: integer; n: integer);
+			begin
+				sum := sum + n;
+			end;
+			procedure twice(var x: integer);
+			begin
+				add(x, 1);
+				add(x, 1);
+			end;
+			twice(r);`,
+			expected: map[string]int64{"r": 2},
+		},
+		{
+			input: `n: integer;
+			procedure bump;
+			begin
+				n := n + 1;
+			end;
+			bump;
+			bump();`,
+			expected: map[string]int64{"n": 2},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors: %v", p.Errors())
+		}
+		env := object.NewEnvironment()
+		if result := Eval(program, env); isError(result) {
+			t.Fatalf("evaluation failed: %s", result.Inspect())
+		}
+
+		for key, val := range tt.expected {
+			env_var, ok := env.Get(key)
+			if !ok {
+				t.Fatalf("variable %s not exist", key)
+			}
+
+			integer_obj, ok := env_var.(*object.Integer)
+			if !ok {
+				t.Fatalf("env_var is not integer go %T", env_var)
+			}
+
+			if integer_obj.Value != val {
+				t.Fatalf("value of %s is not %d, got %d", key, val, integer_obj.Value)
+			}
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"p(1);", "procedure not found: p"},
+		{"procedure p(x: integer); begin end; p;", "wrong number of arguments to p: want=1, got=0"},
+		{"procedure p; begin end; a: integer; a := p;", "line 1, column 42: procedure p does not return a value"},
+		{"a: integer; a(1);", "not a procedure: a"},
+		{"procedure p(x: integer); begin end; p(1, 2);", "wrong number of arguments to p: want=1, got=2"},
+		{"procedure p(var x: integer); begin end; p(1);", "var parameter x of p needs a variable, got 1"},
+		{"procedure p; begin x := 1 / 0; end; p();", "division by zero: 1 / 0"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func testNullObject(t *testing.T, obj object.Object) bool {
 	if obj != NULL {
 		t.Errorf("object is not NULL. got=%T (%+v)", obj, obj)
@@ -293,27 +755,1040 @@ func testCompObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+// newTestRuntime keeps the integer "/" most of these tests were written
+// for; TestDivision covers the default.
+func newTestRuntime() *object.Runtime {
+	rt := object.NewRuntime()
+	rt.IntegerDivide = true
+	return rt
+}
+
 func testEval(input string) object.Object {
+	return testEvalWithRuntime(input, newTestRuntime())
+}
+
+func testEvalWithRuntime(input string, rt *object.Runtime) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
 	program := p.ParseProgram()
 	// fmt.Printf("type of %q is %T\n", input, program.Statements[0])
-	env := object.NewEnvironment()
+	env := object.NewEnvironmentWithRuntime(rt)
 
 	return Eval(program, env)
 }
 
-func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
-	result, ok := obj.(*object.Integer)
+// testReadProgram runs a program that reads its input from rt and returns
+// its environment, so that the values read can be checked.
+func testReadProgram(t *testing.T, input string, rt *object.Runtime) *object.Environment {
+	env := object.NewEnvironmentWithRuntime(rt)
+	if result := Eval(parser.New(lexer.New(input)).ParseProgram(), env); isError(result) {
+		t.Fatalf("evaluation failed: %s", result.Inspect())
+	}
+	return env
+}
+
+// testInspect compares what the result of input prints as.
+func testInspect(t *testing.T, input string, obj object.Object, expected string) bool {
+	if obj.Inspect() != expected {
+		t.Errorf("wrong result for %q. got=%s, want=%s", input, obj.Inspect(), expected)
+		return false
+	}
+	return true
+}
+
+func testErrorObject(t *testing.T, obj object.Object, expected string) bool {
+	errObj, ok := obj.(*object.Error)
 	if !ok {
-		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		t.Errorf("object is not Error. got=%T (%+v)", obj, obj)
 		return false
 	}
-	if result.Value != expected {
-		t.Errorf("object has wrong value. got=%d, want=%d",
-			result.Value, expected)
+	if errObj.Message != expected {
+		t.Errorf("wrong error message. got=%q, want=%q",
+			errObj.Message, expected)
 		return false
 	}
+	return true
+}
+
+func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
+	result, ok := obj.(*object.Integer)
+	if !ok {
+		t.Errorf("object is not Integer. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%d, want=%d",
+			result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"function double(n: integer): integer; begin return n * 2; end; double(21);", 42},
+		{"function double(n: integer): integer; begin return n * 2; end; double(double(2)) + 1;", 9},
+		{
+			`function fib(n: integer): integer;
+			begin
+				if n < 2 then
+					return n;
+				end;
+				return fib(n - 1) + fib(n - 2);
+			end;
+			fib(15);`,
+			610,
+		},
+		{
+			`function fact(n: integer): integer;
+			begin
+				r: integer;
+				r := 1;
+				for i := 2 to n do
+					r := r * i;
+				end;
+				return r;
+			end;
+			i: integer;
+			fact(10);`,
+			3628800,
+		},
+		{
+			`function first(n: integer): integer;
+			begin
+				loop begin
+					if n = 35 then
+						return n;
+					end;
+					n := n + 1;
+				end;
+			end;
+			first(30);`,
+			35,
+		},
+		{
+			`n: integer;
+			function next: integer;
+			begin
+				n := n + 1;
+				return n;
+			end;
+			next;
+			next + next() * 10;`,
+			32,
+		},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"function f(n: integer): integer; begin end; f(1);", "function f ended without returning a value"},
+		{"function f: integer; begin return; end; f() + 1;", "function f ended without returning a value"},
+		{"function f: integer; begin return; end; f + 1;", "function f ended without returning a value"},
+		{"procedure p; begin end; p() + 1;", "procedure p does not return a value"},
+		{"a: integer; a(1) + 1;", "not a function: a"},
+		{"function f(n: integer): integer; begin return 1 / n; end; f(0);", "division by zero: 1 / 0"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCallDepthLimit(t *testing.T) {
+	input := `function down(n: integer): integer;
+	begin
+		if n = 0 then
+			return 0;
+		end;
+		return down(n - 1);
+	end;`
+
+	rt := newTestRuntime()
+	rt.MaxCallDepth = 100
+
+	testIntegerObject(t, testEvalWithRuntime(input+"down(99);", rt), 0)
+	testErrorObject(t, testEvalWithRuntime(input+"down(100);", rt),
+		"call depth limit of 100 exceeded in down")
+
+	if rt.CallDepth != 0 {
+		t.Errorf("call depth not restored after error. got=%d", rt.CallDepth)
+	}
+
+	testErrorObject(t, testEval("procedure p; begin p(); end; p();"),
+		"call depth limit of 10000 exceeded in p")
+}
+
+func TestStringExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"hello";`, "hello"},
+		{`"sum = " + "42";`, "sum = 42"},
+		{`s: string; s := "ab"; s := s + s + "c"; s;`, "ababc"},
+		{`s: string; s;`, ""},
+		{`"a\tb";`, "a\tb"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("String has wrong value. got=%q, want=%q", str.Value, tt.expected)
+		}
+	}
+
+	comparisons := []struct {
+		input    string
+		expected int64
+	}{
+		{`"abc" = "abc";`, 1},
+		{`"abc" <> "abc";`, 0},
+		{`"abc" < "abd";`, 1},
+		{`"b" >= "abc";`, 1},
+		{`"" <= "a";`, 1},
+	}
+
+	for _, tt := range comparisons {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a" + 1;`, "type mismatch: STRING + INTEGER"},
+		{`"a" - "b";`, "unknown operator: STRING - STRING"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestWriteExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`write "sum = ", 1 + 2, skip;`, "sum = 3\n"},
+		{`write 1, space, 2, tab, 3;`, "1 2\t3"},
+		{`a: integer; a := 5; write "a", space, "=", space, a;`, "a = 5"},
+		{`i: integer; for i := 1 to 3 do write i, skip; end;`, "1\n2\n3\n"},
+		{`write 42:5, "|", "ab":4, "|", 'c':2, "|", 12345:2;`, "   42|  ab| c|12345"},
+		{`write 3.14159:8:2, "|", 2.5:0:0, "|", 7:6:1, "|", -0.5:5;`, "    3.14|2|   7.0| -0.5"},
+		{`n: integer; n := 3; write 1.0 / 3.0:n + 3:n;`, " 0.333"},
+		{`write 10:B, space, 8:C, space, 255:H, space, 26:H, space, 0:H, space, -10:B;`, "1010B 10C 0FFH 1AH 0H -1010B"},
+		{`write 255:6:H;`, "  0FFH"},
+	}
 
+	for _, tt := range tests {
+		var out bytes.Buffer
+		rt := newTestRuntime()
+		rt.Out = &out
+
+		if result := testEvalWithRuntime(tt.input, rt); isError(result) {
+			t.Fatalf("evaluation failed: %s", result.Inspect())
+		}
+
+		if out.String() != tt.expected {
+			t.Errorf("wrong output. got=%q, want=%q", out.String(), tt.expected)
+		}
+	}
+
+	testErrorObject(t, testEval(`write "x", 1 / 0;`), "division by zero: 1 / 0")
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`write 1.5:H;`, "line 1, column 10: base suffix H needs an INTEGER, got REAL"},
+		{`write "x":4:2;`, "line 1, column 10: precision needs a REAL, got STRING"},
+		{`write 1:-1;`, "line 1, column 8: invalid field width -1"},
+		{`write 1.5:4:1.5;`, "line 1, column 10: invalid precision 1.5"},
+		{`v: vector[2] of integer; write v:4;`, "line 1, column 33: cannot format VECTOR"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// base output reads back as the same number
+	rt := newTestRuntime()
+	rt.Arithmetic = object.ARITH_BIG
+	var out bytes.Buffer
+	rt.Out = &out
+	testEvalWithRuntime("write -99999999999999999999:H;", rt)
+	evaluated := testEvalWithRuntime(out.String()+";", rt)
+	if evaluated.Inspect() != "-99999999999999999999" {
+		t.Errorf("wrong round trip of %s. got=%s", out.String(), evaluated.Inspect())
+	}
+}
+
+func TestReadInput(t *testing.T) {
+	rt := newTestRuntime()
+	rt.In = strings.NewReader("7 alice\n-3")
+
+	env := testReadProgram(t, `a, b: integer; s: string; read a, s, b;`, rt)
+
+	a, _ := env.Get("a")
+	testIntegerObject(t, a, 7)
+	b, _ := env.Get("b")
+	testIntegerObject(t, b, -3)
+
+	s, _ := env.Get("s")
+	if str, ok := s.(*object.String); !ok || str.Value != "alice" {
+		t.Errorf("s is not String %q. got=%T (%+v)", "alice", s, s)
+	}
+
+	errorTests := []struct {
+		input    string
+		program  string
+		expected string
+	}{
+		{"abc", "a: integer; read a;", `line 1, column 13: cannot read "abc" into a: not an integer`},
+		{"12x", "a: integer; read a;", `line 1, column 13: cannot read "12x" into a: not an integer`},
+		{"1 x 3", "v: vector[3] of integer; read v;", `line 1, column 26: cannot read "x" into v: not an integer`},
+		{"1.5.", "x: real; read x;", `line 1, column 10: cannot read "1.5." into x: not a real number`},
+		{"99999999999999999999", "a: integer; read a;", "line 1, column 13: input 99999999999999999999 out of range for 64-bit integers"},
+		{"", "a: integer; read a;", "line 1, column 13: unexpected end of input reading a"},
+		{"1 2", "v: vector[3] of integer; read v;", "line 1, column 26: unexpected end of input reading v"},
+		{"", "s: string; read s;", "line 1, column 12: unexpected end of input reading s"},
+		{"", "c: char; read c;", "line 1, column 10: unexpected end of input reading c"},
+	}
+
+	for _, tt := range errorTests {
+		rt := newTestRuntime()
+		rt.In = strings.NewReader(tt.input)
+		testErrorObject(t, testEvalWithRuntime(tt.program, rt), tt.expected)
+	}
+
+	rt = newTestRuntime()
+	rt.Arithmetic = object.ARITH_CHECKED
+	rt.WordSize = 16
+	rt.In = strings.NewReader("40000")
+	testErrorObject(t, testEvalWithRuntime("a: integer; read a;", rt),
+		"line 1, column 13: input 40000 out of range for 16-bit integers")
+
+	rt = newTestRuntime()
+	rt.Arithmetic = object.ARITH_BIG
+	rt.In = strings.NewReader("1e3")
+	testErrorObject(t, testEvalWithRuntime("a: integer; read a;", rt),
+		`line 1, column 13: cannot read "1e3" into a: not an integer`)
+}
+
+func TestRealExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"2.5;", 2.5},
+		{"-2.5;", -2.5},
+		{"1.5 + 2.25;", 3.75},
+		{"1.5 * 4. - .5;", 5.5},
+		{"1. / 4.;", 0.25},
+		{"x: real; x;", 0},
+		{"x: real; x := 1.5; x := x * x; x;", 2.25},
+	}
+
+	for _, tt := range tests {
+		testRealObject(t, testEval(tt.input), tt.expected)
+	}
+
+	comparisons := []struct {
+		input    string
+		expected int64
+	}{
+		{"1.5 < 2.5;", 1},
+		{"1.5 >= 2.5;", 0},
+		{"0.1 + 0.2 = 0.3;", 0},
+		{"not 0.0;", 1},
+	}
+
+	for _, tt := range comparisons {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testErrorObject(t, testEval("1.5 / 0.0;"), "division by zero: 1.5 / 0.0")
+	testErrorObject(t, testEval(`1.5 + "a";`), "type mismatch: REAL + STRING")
+}
+
+func TestBuiltinFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"abs(-5);", 5},
+		{"abs(5);", 5},
+		{"abs(-2.5);", 2.5},
+		{"sqr(-7);", 49},
+		{"sqr(1.5);", 2.25},
+		{"sqrt(16);", 4.0},
+		{"sqrt(2.25);", 1.5},
+		{"sin(0);", 0.0},
+		{"cos(0.0);", 1.0},
+		{"exp(0);", 1.0},
+		{"ln(1);", 0.0},
+		{"trunc(2.7);", 2},
+		{"trunc(-2.7);", -2},
+		{"trunc(7);", 7},
+		{"round(2.5);", 3},
+		{"round(-2.5);", -3},
+		{"round(2.4);", 2},
+		{"odd(7);", 1},
+		{"odd(-4);", 0},
+		{"min(3, 8);", 3},
+		{"max(3, 8);", 8},
+		{"max(-1.5, -2.5);", -1.5},
+		{"min(1, 2.0);", 1.0},
+		{"max(1.5, 2);", 2.0},
+		{"real(3);", 3.0},
+		{"real(2.5);", 2.5},
+		{"a: integer; a := 3; sqr(a) + abs(a - 10);", 16},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testRealObject(t, evaluated, expected)
+		}
+	}
+
+	rt := newTestRuntime()
+	rt.Arithmetic = object.ARITH_BIG
+	for _, input := range []string{"sqr(10000000000);", "trunc(1e20);"} {
+		evaluated := testEvalWithRuntime(input, rt)
+		if evaluated.Inspect() != "100000000000000000000" {
+			t.Errorf("wrong result for %q. got=%s", input, evaluated.Inspect())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"sqrt(-4);", "line 1, column 1: sqrt of negative number -4"},
+		{"a: real;\na := 1.0 + ln(0.0);", "line 2, column 12: ln of non-positive number 0.0"},
+		{"abs(1, 2);", "line 1, column 1: wrong number of arguments to abs: want=1, got=2"},
+		{`abs("x");`, "line 1, column 1: argument to abs must be INTEGER or REAL, got STRING"},
+		{"odd(1.5);", "line 1, column 1: argument to odd must be INTEGER, got REAL"},
+		{"trunc(1e30);", "line 1, column 1: trunc of 1e+30 out of integer range"},
+		{"sqrt(1 / 0);", "division by zero: 1 / 0"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	rt = newTestRuntime()
+	rt.Arithmetic = object.ARITH_CHECKED
+	testErrorObject(t, testEvalWithRuntime("abs(-9223372036854775807 - 1);", rt),
+		"line 1, column 1: integer overflow: -(-9223372036854775808)")
+}
+
+func testRealObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Real)
+	if !ok {
+		t.Errorf("object is not Real. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g",
+			result.Value, expected)
+		return false
+	}
 	return true
 }
+
+func TestCaseExpression(t *testing.T) {
+	program := `r: integer;
+	case x of
+		1, 2: r := 10;
+		11B: r := 30;
+		-1: begin r := -1; r := r * 100; end;
+		10H: loop begin r := r + 1; exit when r = 5; end;
+	else
+		r := 99;
+	end;
+	r;`
+
+	tests := []struct {
+		x        int64
+		expected int64
+	}{
+		{1, 10},
+		{2, 10},
+		{3, 30},
+		{-1, -100},
+		{16, 5},
+		{7, 99},
+	}
+
+	for _, tt := range tests {
+		rt := newTestRuntime()
+		env := object.NewEnvironmentWithRuntime(rt)
+		env.Set("x", &object.Integer{Value: tt.x})
+		evaluated := Eval(parser.New(lexer.New(program)).ParseProgram(), env)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+
+	testIntegerObject(t, testEval("r: integer; r := 5; case 3 of 1: r := 1; end; r;"), 5)
+	testIntegerObject(t, testEval(`r: integer;
+		case 2 of
+			1: goto over;
+			2: goto over;
+		end;
+		r := 1;
+		over: r;`), 0)
+	testErrorObject(t, testEval(`case "a" of 1: ; end;`), "case expression must be INTEGER, got STRING")
+}
+
+func TestConstStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const n = 10; n * 2;", "20"},
+		{"const n = 3; const m = n * n + 1; m;", "10"},
+		{"const pi = 3.5; pi * 2.0;", "7.0"},
+		{`const title = "sum"; title + ":";`, "sum:"},
+		{"const n = 3; v: vector[n] of integer; v;", "[0, 0, 0]"},
+		{"const n = 2; r: integer; case 2 of 1: r := 1; n: r := 2; end; r;", "2"},
+		{"const n = 2; r: integer; case -2 of -n: r := 5; end; r;", "5"},
+	}
+
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	testErrorObject(t, testEval("const n = 1 / 0;"), "division by zero: 1 / 0")
+	testErrorObject(t, testEval("const n = -1; v: vector[n] of integer;"), "invalid size of vector v: -1")
+}
+
+func TestMatrix(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"m: vector[2, 3] of integer; m;", "[[0, 0, 0], [0, 0, 0]]"},
+		{"m: vector[2] of vector[2] of real; m[1, 2] := 1.5; m;", "[[0.0, 1.5], [0.0, 0.0]]"},
+		{"v: vector[3] of integer; v[2] := 7; v[2] * 2;", "14"},
+		{`m: vector[2, 2] of integer; i, j: integer;
+		  for i := 1 to 2 do
+		    for j := 1 to 2 do
+		      m[i, j] := i * 10 + j;
+		    end;
+		  end;
+		  m;`, "[[11, 12], [21, 22]]"},
+		{"const n = 2; const k = n + 1; m: vector[n, k] of integer; m[n, 3] := 5; m;", "[[0, 0, 0], [0, 0, 5]]"},
+		{"m: vector[2] of vector[3] of integer; m[2][3] := 5; m[2][3] + m[2, 3];", "10"},
+		{"m: vector[2, 3] of integer; m[2, 1] := 4; m[2];", "[4, 0, 0]"},
+		{"c: vector[2, 2, 2] of integer; c[2][1, 2] := 3; c[2];", "[[0, 3], [0, 0]]"},
+		{`m: vector[2, 3] of integer; r: vector[3] of integer;
+		  r[1] := 7; m[1] := r; r[1] := 0; m;`, "[[7, 0, 0], [0, 0, 0]]"},
+		{`m: vector[2, 3] of integer; r: vector[3] of integer;
+		  m[2, 2] := 1; r := m[2]; m[2, 2] := 5; r;`, "[0, 1, 0]"},
+		{`v: vector[4] of integer; i: integer;
+		  v[1] := 1;
+		  for i := 1 to 3 do
+		    v[i + 1] := v[i] * 2;
+		  end;
+		  v[v[2] + 1];`, "4"},
+	}
+
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"m: vector[2, 3] of integer; m[3, 1];", "line 1, column 30: index 3 out of bounds 1..2 in dimension 1 of m"},
+		{"m: vector[2, 3] of integer; m[1, 0] := 1;", "line 1, column 30: index 0 out of bounds 1..3 in dimension 2 of m"},
+		{"m: vector[2, 3] of integer; m[1, 2, 3];", "line 1, column 30: wrong number of indices for m: want=2, got=3"},
+		{"m: vector[2, 3] of integer; m[1][4];", "line 1, column 33: index 4 out of bounds 1..3 in dimension 1 of m[1]"},
+		{"m: vector[2, 3] of integer; r: vector[2] of integer; m[1] := r;",
+			"line 1, column 55: shape mismatch in assignment to m[1]: want vector[3] of integer, got vector[2] of integer"},
+		{"v: vector[0] of integer;", "invalid size of vector v: 0"},
+		{"m: vector[2] of vector[0] of integer;", "invalid size of vector m: 0"},
+		{`v: vector[2] of integer; s: string; v[s];`, "line 1, column 38: vector index must be INTEGER, got STRING"},
+		{"x: integer; x[1];", "line 1, column 14: index operator not supported: INTEGER"},
+		{"v: vector[3] of integer; i: integer; i := 3; v[i + 1] := 0;", "line 1, column 47: index 4 out of bounds 1..3 in dimension 1 of v"},
+		{"v: vector[3] of integer; v[1.5];", "line 1, column 27: vector index must be INTEGER, got REAL"},
+		{"m: vector[100000, 100000] of integer;", "vector m is too large: more than 16777216 elements"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	for _, mode := range []object.ArithmeticMode{object.ARITH_WRAP, object.ARITH_CHECKED} {
+		rt := newTestRuntime()
+		rt.Arithmetic = mode
+		rt.WordSize = 16
+		testErrorObject(t, testEvalWithRuntime("a: vector[40000] of integer;", rt),
+			"size 40000 of vector a out of range for 16-bit integers")
+		testErrorObject(t, testEvalWithRuntime("a: vector[2, 65537] of integer;", rt),
+			"size 65537 of vector a out of range for 16-bit integers")
+		if result := testEvalWithRuntime("a: vector[32767] of integer; a[32767] := 1; a[32767];", rt); result.Inspect() != "1" {
+			t.Errorf("vector of 32767 elements with %s arithmetic. got=%s", mode, result.Inspect())
+		}
+	}
+
+	rt := newTestRuntime()
+	rt.In = strings.NewReader("3 4")
+	env := testReadProgram(t, "m: vector[2, 2] of integer; read m[1, 2], m[2, 1];", rt)
+	m, _ := env.Get("m")
+	if m.Inspect() != "[[0, 3], [4, 0]]" {
+		t.Errorf("read into matrix wrong. got=%s", m.Inspect())
+	}
+
+	rt = newTestRuntime()
+	rt.In = strings.NewReader("5 6")
+	env = testReadProgram(t, "m: vector[2, 2] of integer; read m[2];", rt)
+	m, _ = env.Get("m")
+	if m.Inspect() != "[[0, 0], [5, 6]]" {
+		t.Errorf("read into matrix row wrong. got=%s", m.Inspect())
+	}
+}
+
+func TestVectorOperations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a, b: vector[3] of integer; b[2] := 5; a := b; b[2] := 1; a;", "[0, 5, 0]"},
+		{"a, b: vector[2, 2] of real; b[2, 1] := 1.5; a := b; a;", "[[0.0, 0.0], [1.5, 0.0]]"},
+		{"const n = 2; a: vector[n] of integer; b: vector[2] of integer; b[1] := 3; a := b; a;", "[3, 0]"},
+	}
+
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"a: vector[3] of integer; b: vector[2] of integer; a := b;",
+			"line 1, column 53: shape mismatch in assignment to a: want vector[3] of integer, got vector[2] of integer"},
+		{"a: vector[2, 3] of integer; b: vector[3, 2] of integer; a := b;",
+			"line 1, column 59: shape mismatch in assignment to a: want vector[2, 3] of integer, got vector[3, 2] of integer"},
+		{"a: vector[2] of integer; b: vector[2] of real; a := b;",
+			"line 1, column 50: shape mismatch in assignment to a: want vector[2] of integer, got vector[2] of real"},
+		{"a: vector[2] of integer; a := 1;",
+			"line 1, column 28: type mismatch in assignment to a: want VECTOR, got INTEGER"},
+		{"a: vector[2] of integer; x: integer; x := a;",
+			"line 1, column 40: type mismatch in assignment to x: want INTEGER, got VECTOR"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	writeTests := []struct {
+		input    string
+		expected string
+	}{
+		{"v: vector[3] of integer; v[1] := 1; v[3] := 3; write v, skip;", "1 0 3\n"},
+		{"m: vector[2, 3] of integer; m[2, 1] := 4; write m;", "0 0 0\n4 0 0"},
+		{`v: vector[2] of string; v[1] := "a"; v[2] := "b"; write "[", v, "]";`, "[a b]"},
+		{"m: vector[2, 2] of integer; m[2][1] := 3; write m[2], skip;", "3 0\n"},
+	}
+
+	for _, tt := range writeTests {
+		var out bytes.Buffer
+		rt := newTestRuntime()
+		rt.Out = &out
+
+		if result := testEvalWithRuntime(tt.input, rt); isError(result) {
+			t.Fatalf("evaluation failed: %s", result.Inspect())
+		}
+
+		if out.String() != tt.expected {
+			t.Errorf("wrong output for %q. got=%q, want=%q", tt.input, out.String(), tt.expected)
+		}
+	}
+
+	rt := newTestRuntime()
+	rt.In = strings.NewReader("1 2 3\n4 5 6\n2.5 x")
+	env := testReadProgram(t, `m: vector[2, 3] of integer; r: real; s: string; read m, r, s;`, rt)
+	m, _ := env.Get("m")
+	if m.Inspect() != "[[1, 2, 3], [4, 5, 6]]" {
+		t.Errorf("read into vector wrong. got=%s", m.Inspect())
+	}
+	r, _ := env.Get("r")
+	testRealObject(t, r, 2.5)
+	s, _ := env.Get("s")
+	if s.Inspect() != "x" {
+		t.Errorf("read after vector wrong. got=%s", s.Inspect())
+	}
+}
+
+func TestRecords(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"type point = record x, y: real end; p: point; p;", "point(x: 0.0, y: 0.0)"},
+		{"type point = record x, y: real end; p: point; p.x := 1.5; p.y := p.x * 2.0; p;", "point(x: 1.5, y: 3.0)"},
+		{`type student = record name: string; grades: vector[3] of integer end;
+		  s: student; s.name := "ann"; s.grades[2] := 5; s;`, "student(name: ann, grades: [0, 5, 0])"},
+		{`type point = record x, y: real end;
+		  type segment = record a, b: point end;
+		  s: segment; s.b.x := 1.0; s.b;`, "point(x: 1.0, y: 0.0)"},
+		{`type point = record x, y: real end;
+		  pts: vector[2] of point; pts[1].x := 1.0; pts;`, "[point(x: 1.0, y: 0.0), point(x: 0.0, y: 0.0)]"},
+		{`type point = record x, y: real end;
+		  p, q: point; p.x := 1.0; q := p; p.x := 2.0; q;`, "point(x: 1.0, y: 0.0)"},
+		{`type point = record x, y: real end;
+		  pts: vector[2] of point; p: point; p.y := 4.0; pts[2] := p; p.y := 0.0; pts[2];`, "point(x: 0.0, y: 4.0)"},
+		{`type point = record x, y: real end;
+		  procedure reset(p: point); begin p.x := 0.0; end;
+		  procedure move(var p: point; d: real); begin p.x := p.x + d; end;
+		  p: point; move(p, 2.5); reset(p); p.x;`, "2.5"},
+		{`const n = 2; type row = record cells: vector[n] of integer end; r: row; r;`, "row(cells: [0, 0])"},
+	}
+
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"type point = record x, y: real end; p: point; p.z;", "line 1, column 48: point has no field z"},
+		{"x: integer; x.y := 1;", "line 1, column 14: field access not supported: INTEGER"},
+		{`type a = record x: real end; type b = record x: real end;
+		  p: a; q: b; p := q;`, "line 2, column 19: type mismatch in assignment to p: want a, got b"},
+		{"type point = record x, y: real end; p: point; p := 1;", "line 1, column 49: type mismatch in assignment to p: want point, got INTEGER"},
+		{"type point = record x, y: real end; p: point; read p;", "line 1, column 47: cannot read p of type point"},
+		{"type point = record x, y: real end; v: vector[2] of point; read v;", "line 1, column 60: cannot read v with elements of type point"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	rt := newTestRuntime()
+	rt.In = strings.NewReader("1.5 bob")
+	env := testReadProgram(t, `type s = record x: real; name: string end; v: s; read v.x, v.name;`, rt)
+	v, _ := env.Get("v")
+	if v.Inspect() != "s(x: 1.5, name: bob)" {
+		t.Errorf("read into record wrong. got=%s", v.Inspect())
+	}
+}
+
+func TestDivision(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"7 / 2;", "3.5"},
+		{"6 / 3;", "2.0"},
+		{"7 div 2;", "3"},
+		{"-7 div 2;", "-3"},
+		{"1 + 7 div 2 * 2;", "7"},
+		{"n: integer; n := 9; n div 2 + n / 2;", "8.5"},
+	}
+
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEvalWithRuntime(tt.input, object.NewRuntime()), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 / 0;", "division by zero: 1.0 / 0.0"},
+		{"1 div 0;", "division by zero: 1 div 0"},
+		{"7.0 div 2.0;", "unknown operator: REAL div REAL"},
+		{"n: integer; n := 7 / 2;", "line 1, column 15: type mismatch in assignment to n: want INTEGER, got REAL"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEvalWithRuntime(tt.input, object.NewRuntime()), tt.expected)
+	}
+
+	rt := object.NewRuntime()
+	rt.Arithmetic = object.ARITH_BIG
+	if evaluated := testEvalWithRuntime("99999999999999999999 div 3;", rt); evaluated.Inspect() != "33333333333333333333" {
+		t.Errorf("wrong result for big div. got=%s", evaluated.Inspect())
+	}
+
+	compat := newTestRuntime()
+	testIntegerObject(t, testEvalWithRuntime("7 / 2;", compat), 3)
+	testIntegerObject(t, testEvalWithRuntime("7 div 2;", compat), 3)
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5 + 1;", "2.5"},
+		{"2 * 0.5;", "1.0"},
+		{"1 < 1.5;", "1"},
+		{"r: real; r := 2; r;", "2.0"},
+		{"r: real; r := 2; r / 4;", "0.5"},
+		{"v: vector[2] of real; v[1] := 1; v[2] := v[1] + 1; v;", "[1.0, 2.0]"},
+		{"type p = record x: real; end; a: p; a.x := 7; a.x;", "7.0"},
+		{"function half(x: real): real; begin return x / 2; end; half(3);", "1.5"},
+		{"function one(): real; begin return 1; end; one();", "1.0"},
+		{"real(7) / 2;", "3.5"},
+		{"trunc(real(7) / 2);", "3"},
+		{"round(real(7) / 2);", "4"},
+		{"n: integer; n := 5; real(n);", "5.0"},
+	}
+
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`real("1");`, "line 1, column 1: argument to real must be INTEGER or REAL, got STRING"},
+		{"real(1, 2);", "line 1, column 1: wrong number of arguments to real: want=1, got=2"},
+		{"n: integer; n := 1.5;", "line 1, column 15: type mismatch in assignment to n: want INTEGER, got REAL"},
+		{"v: vector[2] of integer; v[1] := 0.5;", "line 1, column 27: type mismatch in assignment to v[1]: want INTEGER, got REAL"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	files := object.NewMemFileSystem()
+	files.WriteFile("data.txt", []byte("1 2.5 abc\nxy"))
+
+	rt := newTestRuntime()
+	rt.Files = files
+	env := testReadProgram(t, `f: file; n: integer; r: real; s: string; a, b, c: char;
+assign(f, "data.txt"); reset(f);
+read f, n, r, s, a, b, c;
+close(f);
+rewrite(f); write f, n, space, r, space, s, b, c; close(f);`, rt)
+
+	expected := map[string]string{"n": "1", "r": "2.5", "s": "abc", "a": "\n", "b": "x", "c": "y"}
+	for name, want := range expected {
+		val, _ := env.Get(name)
+		if val.Inspect() != want {
+			t.Errorf("%s has wrong value. got=%q, want=%q", name, val.Inspect(), want)
+		}
+	}
+
+	data, _ := files.ReadFile("data.txt")
+	if string(data) != "1 2.5 abcxy" {
+		t.Errorf("wrong file contents. got=%q", data)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`f: file; assign(f, "data.txt"); reset(f); eof(f);`, "0"},
+		{`f: file; assign(f, "empty"); rewrite(f); close(f); reset(f); eof(f);`, "1"},
+		{`f: file; assign(f, "data.txt"); f;`, `file "data.txt"`},
+		{`f, g: file; assign(f, "copy"); rewrite(f); g := f; write g, 7; close(g);
+		  assign(f, "copy"); reset(f); n: integer; read f, n; n;`, "7"},
+	}
+
+	for _, tt := range tests {
+		rt := newTestRuntime()
+		rt.Files = files
+		testInspect(t, tt.input, testEvalWithRuntime(tt.input, rt), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`f: file; reset(f);`, "line 1, column 10: reset of a file without a name: call assign first"},
+		{`f: file; assign(f, "missing"); reset(f);`, "line 1, column 32: cannot open missing: open missing: file does not exist"},
+		{`f: file; n: integer; assign(f, "data.txt"); read f, n;`, "line 1, column 45: file \"data.txt\" is not open for reading"},
+		{`f: file; assign(f, "data.txt"); reset(f); write f, 1;`, "line 1, column 43: file \"data.txt\" is not open for writing"},
+		{`f: file; eof(f);`, "line 1, column 10: file \"\" is not open for reading"},
+		{`assign(1, "x");`, "line 1, column 1: argument to assign must be FILE, got INTEGER"},
+		{`f: file; assign(f, 1);`, "line 1, column 10: argument 2 to assign must be STRING, got INTEGER"},
+		{`f: file; write 1, f;`, "line 1, column 10: cannot write f of type file"},
+		{`v: vector[2] of file; read v;`, "line 1, column 23: cannot read v with elements of type file"},
+	}
+
+	for _, tt := range errorTests {
+		rt := newTestRuntime()
+		rt.Files = files
+		testErrorObject(t, testEvalWithRuntime(tt.input, rt), tt.expected)
+	}
+}
+
+func TestAssertAndHalt(t *testing.T) {
+	testIntegerObject(t, testEval("n: integer; n := 3; assert n > 0; n;"), 3)
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"n: integer;\nassert n > 0;", "line 2, column 1: assertion failed: (n > 0)"},
+		{`s: string; if 1 then assert s = "x"; end;`, `line 1, column 22: assertion failed: (s = "x")`},
+		{"assert 1 / 0;", "division by zero: 1 / 0"},
+		{`halt("x");`, "line 1, column 1: argument to halt must be INTEGER, got STRING"},
+		{"halt(256);", "line 1, column 1: exit code 256 out of range 0..255"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	tests := []struct {
+		input  string
+		code   int
+		output string
+	}{
+		{"write 1; halt(3); write 2;", 3, "1"},
+		{"i: integer; for i := 1 to 5 do write i; if i = 2 then halt(0); end; end;", 0, "12"},
+		{"procedure stop(); begin halt(4); end; loop begin stop(); write 1; end;", 4, ""},
+		{"function f(): integer; begin halt(5); return 1; end; n: integer; n := f() + 1; write n;", 5, ""},
+		{"procedure p(n: integer); begin write n; end; function f(): integer; begin halt(6); end; p(f());", 6, ""},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		rt := newTestRuntime()
+		rt.Out = &out
+
+		evaluated := testEvalWithRuntime(tt.input, rt)
+		halt, ok := evaluated.(*object.Halt)
+		if !ok {
+			t.Errorf("result of %q is not Halt. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if halt.Code != tt.code {
+			t.Errorf("wrong exit code for %q. got=%d, want=%d", tt.input, halt.Code, tt.code)
+		}
+		if out.String() != tt.output {
+			t.Errorf("wrong output for %q. got=%q, want=%q", tt.input, out.String(), tt.output)
+		}
+	}
+}
+
+func TestRandom(t *testing.T) {
+	program := `v: vector[20] of integer; i: integer;
+for i := 1 to 10 do v[i] := random(6); end;
+randomize();
+for i := 11 to 15 do v[i] := random(6); end;
+randomize;
+for i := 16 to 20 do v[i] := random(6); end;
+v;`
+
+	run := func(program string, seed int64) string {
+		rt := newTestRuntime()
+		rt.SetSeed(seed)
+		return testEvalWithRuntime(program, rt).Inspect()
+	}
+
+	first := run(program, 7)
+	if again := run(program, 7); again != first {
+		t.Errorf("same seed gave different numbers. got=%s, want=%s", again, first)
+	}
+	if other := run(program, 8); other == first {
+		t.Errorf("different seeds gave the same numbers: %s", first)
+	}
+	if other := run(strings.Replace(program, "randomize;", "", 1), 7); other == first {
+		t.Errorf("randomize without parentheses did not start new numbers: %s", first)
+	}
+
+	rt := newTestRuntime()
+	for i := 0; i < 100; i++ {
+		n := testEvalWithRuntime("random(3);", rt)
+		integer, ok := n.(*object.Integer)
+		if !ok || integer.Value < 0 || integer.Value > 2 {
+			t.Fatalf("random(3) out of range 0..2. got=%s", n.Inspect())
+		}
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"random(0);", "line 1, column 1: argument to random must be positive, got 0"},
+		{"random(2.5);", "line 1, column 1: argument to random must be INTEGER, got REAL"},
+		{"randomize(1);", "line 1, column 1: wrong number of arguments to randomize: want=0, got=1"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestChars(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"'a';", "a"},
+		{"c: char; c := 'x'; c;", "x"},
+		{"'a' < 'b';", "1"},
+		{"'a' = 'a';", "1"},
+		{"'z' <= 'a';", "0"},
+		{"ord('A');", "65"},
+		{"chr(ord('a') + 1);", "b"},
+		{"chr(233);", "é"},
+		{`"ab" + 'c';`, "abc"},
+		{`'c' + "ab";`, "cab"},
+		{"v: vector[2] of char; v[1] := 'o'; v[2] := 'k'; v;", "[o, k]"},
+	}
+
+	for _, tt := range tests {
+		testInspect(t, tt.input, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"'a' + 'b';", "unknown operator: CHAR + CHAR"},
+		{"'a' + 1;", "type mismatch: CHAR + INTEGER"},
+		{`ord("a");`, "line 1, column 1: argument to ord must be CHAR, got STRING"},
+		{"chr(-1);", "line 1, column 1: chr of -1 is not a character"},
+		{"chr(55296);", "line 1, column 1: chr of 55296 is not a character"},
+	}
+
+	for _, tt := range errorTests {
+		testErrorObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// a reader without UnreadRune, like os.Stdin
+	rt := newTestRuntime()
+	rt.In = struct{ io.Reader }{strings.NewReader("42 xy\nz")}
+	env := testReadProgram(t, `n: integer; a, b, c, d: char; s: string; read n, a, b, c, d, s;`, rt)
+
+	expected := map[string]string{"n": "42", "a": " ", "b": "x", "c": "y", "d": "\n", "s": "z"}
+	for name, want := range expected {
+		val, _ := env.Get(name)
+		if val.Inspect() != want {
+			t.Errorf("%s has wrong value. got=%q, want=%q", name, val.Inspect(), want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package evaluator
+
+import (
+	"bufio"
+	"interp/ast"
+	"interp/object"
+	"io"
+	"unicode"
+)
+
+func builtinAssign(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("assign", args, 2); err != nil {
+		return err
+	}
+
+	file, err := fileArgument("assign", args[0])
+	if err != nil {
+		return err
+	}
+	name, ok := args[1].(*object.String)
+	if !ok {
+		return newError("argument 2 to assign must be STRING, got %s", args[1].Type())
+	}
+
+	if err := closeFile(file); err != nil {
+		return err
+	}
+	file.Name = name.Value
+	return NULL
+}
+
+func builtinReset(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("reset", args, 1); err != nil {
+		return err
+	}
+
+	file, err := openableFile("reset", args[0])
+	if err != nil {
+		return err
+	}
+
+	r, openErr := rt.Files.Open(file.Name)
+	if openErr != nil {
+		return newError("cannot open %s: %s", file.Name, openErr)
+	}
+	file.In = bufio.NewReader(r)
+	file.Closer = r
+	return NULL
+}
+
+func builtinRewrite(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("rewrite", args, 1); err != nil {
+		return err
+	}
+
+	file, err := openableFile("rewrite", args[0])
+	if err != nil {
+		return err
+	}
+
+	w, createErr := rt.Files.Create(file.Name)
+	if createErr != nil {
+		return newError("cannot create %s: %s", file.Name, createErr)
+	}
+	file.Out = w
+	file.Closer = w
+	return NULL
+}
+
+func builtinClose(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("close", args, 1); err != nil {
+		return err
+	}
+
+	file, err := fileArgument("close", args[0])
+	if err != nil {
+		return err
+	}
+	if err := closeFile(file); err != nil {
+		return err
+	}
+	return NULL
+}
+
+// builtinEof skips white space and reports whether the file is exhausted,
+// so that a loop reading numbers stops after the last one.
+func builtinEof(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("eof", args, 1); err != nil {
+		return err
+	}
+
+	file, err := fileArgument("eof", args[0])
+	if err != nil {
+		return err
+	}
+	if file.In == nil {
+		return newError("file %q is not open for reading", file.Name)
+	}
+
+	for {
+		r, _, readErr := file.In.ReadRune()
+		if readErr != nil {
+			return TRUE
+		}
+		if !unicode.IsSpace(r) {
+			file.In.UnreadRune()
+			return FALSE
+		}
+	}
+}
+
+func fileArgument(name string, arg object.Object) (*object.File, *object.Error) {
+	file, ok := arg.(*object.File)
+	if !ok {
+		return nil, newError("argument to %s must be FILE, got %s", name, arg.Type())
+	}
+	return file, nil
+}
+
+// openableFile closes a file about to be opened again. It must have been
+// given a name by assign.
+func openableFile(name string, arg object.Object) (*object.File, *object.Error) {
+	file, err := fileArgument(name, arg)
+	if err != nil {
+		return nil, err
+	}
+	if file.Name == "" {
+		return nil, newError("%s of a file without a name: call assign first", name)
+	}
+	if err := closeFile(file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func closeFile(file *object.File) *object.Error {
+	closer := file.Closer
+	file.In, file.Out, file.Closer = nil, nil, nil
+
+	if closer == nil {
+		return nil
+	}
+	if err := closer.Close(); err != nil {
+		return newError("cannot close %s: %s", file.Name, err)
+	}
+	return nil
+}
+
+// fileArguments splits off a leading file variable from the arguments of
+// read or write. It returns nil for the file when there is none.
+func fileArguments(args []ast.Expression, env *object.Environment) (*object.File, []ast.Expression) {
+	if len(args) == 0 {
+		return nil, args
+	}
+	ident, ok := args[0].(*ast.Identifier)
+	if !ok {
+		return nil, args
+	}
+	if file, ok := lookupFile(ident, env); ok {
+		return file, args[1:]
+	}
+	return nil, args
+}
+
+func lookupFile(ident *ast.Identifier, env *object.Environment) (*object.File, bool) {
+	obj, _ := env.Get(ident.Value)
+	file, ok := obj.(*object.File)
+	return file, ok
+}
+
+// fileInput returns where read scans from: the file if one is given,
+// otherwise the runtime's input.
+func fileInput(node *ast.ReadExpression, file *object.File, rt *object.Runtime) (runeReader, object.Object) {
+	if file == nil {
+		return input(rt), nil
+	}
+	if file.In == nil {
+		return nil, newErrorAt(node.Token, "file %q is not open for reading", file.Name)
+	}
+	return file.In, nil
+}
+
+// fileOutput returns where write prints: the file if one is given,
+// otherwise the runtime's output.
+func fileOutput(node *ast.WriteExpression, file *object.File, rt *object.Runtime) (io.Writer, object.Object) {
+	if file == nil {
+		return rt.Out, nil
+	}
+	if file.Out == nil {
+		return nil, newErrorAt(node.Token, "file %q is not open for writing", file.Name)
+	}
+	return file.Out, nil
+}
@@ -0,0 +1,92 @@
+package evaluator
+
+import (
+	"interp/ast"
+	"interp/object"
+	"interp/token"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var writeBases = map[string]int{"B": 2, "C": 8, "H": 16}
+
+// maxFormatSize bounds widths and precisions, which allocate their padding.
+const maxFormatSize = 1 << 10
+
+// formatWriteItem renders a write argument with format suffixes. The text
+// is right-aligned in the field width; longer text is never cut.
+func formatWriteItem(node *ast.WriteItem, env *object.Environment) (string, object.Object) {
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return "", val
+	}
+
+	var text string
+	switch {
+	case node.Base != "":
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return "", newErrorAt(node.Token, "base suffix %s needs an INTEGER, got %s", node.Base, val.Type())
+		}
+		text = formatInBase(integer, node.Base)
+
+	case node.Precision != nil:
+		precision, err := formatSize(node.Precision, "precision", node.Token, env)
+		if err != nil {
+			return "", err
+		}
+		number, ok := toReal(val).(*object.Real)
+		if !ok {
+			return "", newErrorAt(node.Token, "precision needs a REAL, got %s", val.Type())
+		}
+		text = strconv.FormatFloat(number.Value, 'f', precision, 64)
+
+	default:
+		switch val.(type) {
+		case *object.Vector, *object.Record:
+			return "", newErrorAt(node.Token, "cannot format %s", typeName(val))
+		}
+		text = val.Inspect()
+	}
+
+	if node.Width != nil {
+		width, err := formatSize(node.Width, "field width", node.Token, env)
+		if err != nil {
+			return "", err
+		}
+		if n := width - len([]rune(text)); n > 0 {
+			text = strings.Repeat(" ", n) + text
+		}
+	}
+
+	return text, nil
+}
+
+// formatSize evaluates a field width or precision.
+func formatSize(node ast.Expression, what string, tok token.Token, env *object.Environment) (int, object.Object) {
+	val := Eval(node, env)
+	if isError(val) {
+		return 0, val
+	}
+
+	integer, ok := val.(*object.Integer)
+	if !ok || integer.Big != nil || integer.Value < 0 || integer.Value > maxFormatSize {
+		return 0, newErrorAt(tok, "invalid %s %s", what, val.Inspect())
+	}
+	return int(integer.Value), nil
+}
+
+// formatInBase writes an integer the way a literal in that base is
+// spelled, so that it reads back as the same number: 255 is 0FFH.
+func formatInBase(integer *object.Integer, suffix string) string {
+	value := integer.BigValue()
+	digits := strings.ToUpper(new(big.Int).Abs(value).Text(writeBases[suffix]))
+	if suffix == "H" && (digits[0] < '0' || digits[0] > '9') {
+		digits = "0" + digits
+	}
+	if value.Sign() < 0 {
+		return "-" + digits + suffix
+	}
+	return digits + suffix
+}
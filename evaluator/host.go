@@ -0,0 +1,233 @@
+package evaluator
+
+import (
+	"fmt"
+	"interp/object"
+	"math/big"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// HostSignature describes a Go function in the language's types. Parameters
+// and results may be signed or unsigned integers (integer), floats (real),
+// strings, bools (integer, true being 1) and slices of those (one-dimensional
+// vector). The function returns at most one such value, optionally followed
+// by an error; an empty result means it returns no value.
+func HostSignature(fn interface{}) (params []string, result string, err error) {
+	t := reflect.TypeOf(fn)
+	if t == nil || t.Kind() != reflect.Func {
+		return nil, "", fmt.Errorf("%T is not a function", fn)
+	}
+	if t.IsVariadic() {
+		return nil, "", fmt.Errorf("variadic functions are not supported")
+	}
+
+	for i := 0; i < t.NumIn(); i++ {
+		typ, ok := hostType(t.In(i))
+		if !ok {
+			return nil, "", fmt.Errorf("unsupported parameter type %s", t.In(i))
+		}
+		params = append(params, typ)
+	}
+
+	outs := t.NumOut()
+	if outs > 0 && t.Out(outs-1) == errorType {
+		outs--
+	}
+
+	switch outs {
+	case 0:
+	case 1:
+		typ, ok := hostType(t.Out(0))
+		if !ok {
+			return nil, "", fmt.Errorf("unsupported result type %s", t.Out(0))
+		}
+		result = typ
+	default:
+		return nil, "", fmt.Errorf("functions may return at most one value and an error")
+	}
+
+	return params, result, nil
+}
+
+// NewHostBuiltin wraps a Go function as a builtin, converting arguments and
+// results as described by HostSignature. A non-nil error returned by the
+// function becomes a runtime error of the program.
+func NewHostBuiltin(name string, fn interface{}) (*object.Builtin, error) {
+	if _, _, err := HostSignature(fn); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+
+	call := func(rt *object.Runtime, args ...object.Object) object.Object {
+		if err := checkArity(name, args, ft.NumIn()); err != nil {
+			return err
+		}
+
+		in := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			val, errObj := toHostValue(arg, ft.In(i))
+			if errObj != nil {
+				return newError("argument %d to %s: %s", i+1, name, errObj.Message)
+			}
+			in[i] = val
+		}
+
+		out := fv.Call(in)
+
+		if n := len(out); n > 0 && ft.Out(n-1) == errorType {
+			if err, _ := out[n-1].Interface().(error); err != nil {
+				return newError("%s: %s", name, err)
+			}
+			out = out[:n-1]
+		}
+
+		if len(out) == 0 {
+			return NULL
+		}
+
+		result, errObj := fromHostValue(out[0], rt)
+		if errObj != nil {
+			return newError("result of %s: %s", name, errObj.Message)
+		}
+		return result
+	}
+
+	return &object.Builtin{Name: name, Fn: call}, nil
+}
+
+func hostType(t reflect.Type) (string, bool) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Bool:
+		return "integer", true
+	case reflect.Float32, reflect.Float64:
+		return "real", true
+	case reflect.String:
+		return "string", true
+	case reflect.Slice:
+		if elem, ok := hostType(t.Elem()); ok && elem != "vector" {
+			return "vector", true
+		}
+	}
+	return "", false
+}
+
+func toHostValue(obj object.Object, t reflect.Type) (reflect.Value, *object.Error) {
+	val := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return val, newError("want INTEGER, got %s", obj.Type())
+		}
+		if integer.Big != nil || val.OverflowInt(integer.Value) {
+			return val, newError("%s out of range for %s", integer.Inspect(), t)
+		}
+		val.SetInt(integer.Value)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return val, newError("want INTEGER, got %s", obj.Type())
+		}
+		n := integer.BigValue()
+		if n.Sign() < 0 || !n.IsUint64() || val.OverflowUint(n.Uint64()) {
+			return val, newError("%s out of range for %s", integer.Inspect(), t)
+		}
+		val.SetUint(n.Uint64())
+
+	case reflect.Bool:
+		val.SetBool(isTruthy(obj))
+
+	case reflect.Float32, reflect.Float64:
+		x, errObj := realArgument("", obj)
+		if errObj != nil {
+			return val, newError("want INTEGER or REAL, got %s", obj.Type())
+		}
+		val.SetFloat(x)
+
+	case reflect.String:
+		str, ok := obj.(*object.String)
+		if !ok {
+			return val, newError("want STRING, got %s", obj.Type())
+		}
+		val.SetString(str.Value)
+
+	case reflect.Slice:
+		vector, ok := obj.(*object.Vector)
+		if !ok {
+			return val, newError("want VECTOR, got %s", obj.Type())
+		}
+		if len(vector.Dims) > 1 {
+			return val, newError("want a one-dimensional VECTOR, got %d dimensions", len(vector.Dims))
+		}
+		val = reflect.MakeSlice(t, len(vector.Elements), len(vector.Elements))
+		for i, element := range vector.Elements {
+			elem, errObj := toHostValue(element, t.Elem())
+			if errObj != nil {
+				return val, newError("element %d: %s", i+1, errObj.Message)
+			}
+			val.Index(i).Set(elem)
+		}
+	}
+
+	return val, nil
+}
+
+func fromHostValue(val reflect.Value, rt *object.Runtime) (object.Object, *object.Error) {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return hostInteger(new(big.Int).SetInt64(val.Int()), rt)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return hostInteger(new(big.Int).SetUint64(val.Uint()), rt)
+
+	case reflect.Bool:
+		return nativeCmp(val.Bool()), nil
+
+	case reflect.Float32, reflect.Float64:
+		return &object.Real{Value: val.Float()}, nil
+
+	case reflect.String:
+		return &object.String{Value: val.String()}, nil
+
+	case reflect.Slice:
+		elementType, _ := hostType(val.Type().Elem())
+		vector := &object.Vector{
+			ElementType: elementType,
+			Dims:        []int{val.Len()},
+			Elements:    make([]object.Object, val.Len()),
+		}
+		for i := range vector.Elements {
+			element, errObj := fromHostValue(val.Index(i), rt)
+			if errObj != nil {
+				return nil, newError("element %d: %s", i+1, errObj.Message)
+			}
+			vector.Elements[i] = element
+		}
+		return vector, nil
+	}
+
+	return nil, newError("unsupported type %s", val.Type())
+}
+
+// hostInteger brings an integer from Go into the runtime's range. Unlike
+// program arithmetic it never wraps: a host value that does not fit is an
+// error in every mode but big.
+func hostInteger(n *big.Int, rt *object.Runtime) (object.Object, *object.Error) {
+	if rt.Arithmetic == object.ARITH_BIG {
+		return object.NewBigInteger(n), nil
+	}
+
+	if !n.IsInt64() || n.Int64() < rt.MinInt() || n.Int64() > rt.MaxInt() {
+		return nil, newError("%s out of range for %d-bit integers", n, rt.WordSize)
+	}
+
+	return &object.Integer{Value: n.Int64()}, nil
+}
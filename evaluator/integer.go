@@ -0,0 +1,111 @@
+package evaluator
+
+import (
+	"interp/object"
+	"math"
+	"math/big"
+)
+
+func evalIntegerArithmetic(
+	operator string,
+	left, right int64,
+	rt *object.Runtime,
+) object.Object {
+	var result int64
+	var overflow bool
+
+	switch operator {
+	case "+":
+		result = left + right
+		overflow = (left >= 0) == (right >= 0) && (result >= 0) != (left >= 0)
+	case "-":
+		result = left - right
+		overflow = (left >= 0) != (right >= 0) && (result >= 0) != (left >= 0)
+	case "*":
+		result = left * right
+		overflow = left != 0 && (result/left != right || left == -1 && right == math.MinInt64)
+	case "/", "div":
+		if right == 0 {
+			return newError("division by zero: %d %s %d", left, operator, right)
+		}
+		result = left / right
+		overflow = left == math.MinInt64 && right == -1
+	}
+
+	if overflow && rt.Arithmetic == object.ARITH_BIG {
+		return evalBigArithmetic(operator, big.NewInt(left), big.NewInt(right))
+	}
+
+	return fitInteger(rt, result, overflow,
+		"integer overflow: %d %s %d", left, operator, right)
+}
+
+func evalBigArithmetic(operator string, left, right *big.Int) object.Object {
+	result := new(big.Int)
+
+	switch operator {
+	case "+":
+		result.Add(left, right)
+	case "-":
+		result.Sub(left, right)
+	case "*":
+		result.Mul(left, right)
+	case "/", "div":
+		if right.Sign() == 0 {
+			return newError("division by zero: %s %s %s", left, operator, right)
+		}
+		result.Quo(left, right)
+	}
+
+	return object.NewBigInteger(result)
+}
+
+func evalBigComparison(operator string, left, right *big.Int) object.Object {
+	cmp := left.Cmp(right)
+
+	switch operator {
+	case "<":
+		return nativeCmp(cmp < 0)
+	case ">":
+		return nativeCmp(cmp > 0)
+	case "<=":
+		return nativeCmp(cmp <= 0)
+	case ">=":
+		return nativeCmp(cmp >= 0)
+	case "=":
+		return nativeCmp(cmp == 0)
+	case "<>":
+		return nativeCmp(cmp != 0)
+	default:
+		return newError("unknown operator: %s %s %s",
+			object.INTEGER_OBJ, operator, object.INTEGER_OBJ)
+	}
+}
+
+// fitInteger brings a 64-bit result into the runtime's word size: in wrap
+// mode the high bits are dropped, in checked mode anything out of range is
+// reported with the given message. Big mode never loses precision, so the
+// callers only get here with values that fit.
+func fitInteger(
+	rt *object.Runtime,
+	value int64,
+	overflow bool,
+	format string, a ...interface{},
+) object.Object {
+	switch rt.Arithmetic {
+	case object.ARITH_CHECKED:
+		if overflow || value < rt.MinInt() || value > rt.MaxInt() {
+			return newError(format, a...)
+		}
+		return &object.Integer{Value: value}
+	case object.ARITH_BIG:
+		return &object.Integer{Value: value}
+	}
+
+	return &object.Integer{Value: wrapInteger(value, rt.WordSize)}
+}
+
+func wrapInteger(value int64, bits int) int64 {
+	shift := 64 - bits
+	return value << shift >> shift
+}
@@ -0,0 +1,80 @@
+package evaluator
+
+import "interp/object"
+
+func evalRealInfixExpression(
+	operator string,
+	left, right object.Object,
+) object.Object {
+	leftVal := left.(*object.Real).Value
+	rightVal := right.(*object.Real).Value
+
+	switch operator {
+	case "+":
+		return &object.Real{Value: leftVal + rightVal}
+	case "-":
+		return &object.Real{Value: leftVal - rightVal}
+	case "*":
+		return &object.Real{Value: leftVal * rightVal}
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero: %s / %s", left.Inspect(), right.Inspect())
+		}
+		return &object.Real{Value: leftVal / rightVal}
+	case "<":
+		return nativeCmp(leftVal < rightVal)
+	case ">":
+		return nativeCmp(leftVal > rightVal)
+	case "<=":
+		return nativeCmp(leftVal <= rightVal)
+	case ">=":
+		return nativeCmp(leftVal >= rightVal)
+	case "=":
+		return nativeCmp(leftVal == rightVal)
+	case "<>":
+		return nativeCmp(leftVal != rightVal)
+	default:
+		return newError("unknown operator: %s %s %s",
+			left.Type(), operator, right.Type())
+	}
+}
+
+// toReal widens an integer to a real; any other value is returned as is.
+// Integers are widened wherever a real is expected: in arithmetic with a
+// real, and when stored in a real variable, element, field or parameter.
+func toReal(obj object.Object) object.Object {
+	if _, ok := obj.(*object.Integer); !ok {
+		return obj
+	}
+	x, _ := realArgument("", obj)
+	return &object.Real{Value: x}
+}
+
+// widenLike widens val when it replaces a real.
+func widenLike(current, val object.Object) object.Object {
+	if _, ok := current.(*object.Real); ok {
+		return toReal(val)
+	}
+	return val
+}
+
+// narrows reports whether storing val in place of current would silently
+// drop the fraction of a real; trunc or round must be used instead.
+func narrows(current, val object.Object) bool {
+	_, isInteger := current.(*object.Integer)
+	_, isReal := val.(*object.Real)
+	return isInteger && isReal
+}
+
+func builtinReal(rt *object.Runtime, args ...object.Object) object.Object {
+	if err := checkArity("real", args, 1); err != nil {
+		return err
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.Real:
+		return toReal(arg)
+	default:
+		return numberArgumentError("real", arg)
+	}
+}
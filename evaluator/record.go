@@ -0,0 +1,121 @@
+package evaluator
+
+import (
+	"interp/ast"
+	"interp/object"
+)
+
+// newValue returns the zero value of a declared type: a basic type or the
+// name of a record type.
+func newValue(typ string, env *object.Environment) object.Object {
+	switch typ {
+	case "integer", "real", "string", "char", "file":
+		return zeroValue(typ)
+	}
+
+	obj, _ := env.Get(typ)
+	recordType, ok := obj.(*object.RecordType)
+	if !ok {
+		return newError("unknown type %s", typ)
+	}
+
+	return newRecord(recordType)
+}
+
+// newRecord declares the fields of a record type in a scope of their own
+// and collects them.
+func newRecord(recordType *object.RecordType) object.Object {
+	record := &object.Record{
+		TypeName: recordType.Name,
+		Fields:   make(map[string]object.Object),
+	}
+	scope := object.NewEnclosedEnvironment(recordType.Env)
+
+	for _, field := range recordType.Fields {
+		if val := Eval(field, scope); isError(val) {
+			return val
+		}
+
+		var name string
+		switch field := field.(type) {
+		case *ast.DeclStatment:
+			name = field.Name.Value
+		case *ast.DeclStatmentVector:
+			name = field.Name.Value
+		}
+
+		val, _ := scope.Get(name)
+		record.Names = append(record.Names, name)
+		record.Fields[name] = val
+	}
+
+	return record
+}
+
+func evalFieldExpression(
+	node *ast.FieldExpression,
+	env *object.Environment,
+) object.Object {
+	record, err := recordField(node, env)
+	if err != nil {
+		return err
+	}
+	return record.Fields[node.Field.Value]
+}
+
+// recordField finds the record holding the field an expression refers to.
+func recordField(
+	node *ast.FieldExpression,
+	env *object.Environment,
+) (*object.Record, object.Object) {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return nil, left
+	}
+
+	record, ok := left.(*object.Record)
+	if !ok {
+		return nil, newErrorAt(node.Token, "field access not supported: %s", left.Type())
+	}
+	if _, ok := record.Fields[node.Field.Value]; !ok {
+		return nil, newErrorAt(node.Token, "%s has no field %s", record.TypeName, node.Field.Value)
+	}
+
+	return record, nil
+}
+
+// copyValue copies vectors and records, which are changed in place, so
+// that a stored value is not shared with the variable it came from.
+func copyValue(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Vector:
+		elements := make([]object.Object, len(obj.Elements))
+		for i, element := range obj.Elements {
+			elements[i] = copyValue(element)
+		}
+		return &object.Vector{ElementType: obj.ElementType, Dims: obj.Dims, Elements: elements}
+
+	case *object.Record:
+		fields := make(map[string]object.Object, len(obj.Fields))
+		for name, field := range obj.Fields {
+			fields[name] = copyValue(field)
+		}
+		return &object.Record{TypeName: obj.TypeName, Names: obj.Names, Fields: fields}
+
+	case *object.File:
+		// the checker rejects copies of files; one made without it refers
+		// to the same open file
+		file := *obj
+		return &file
+	}
+
+	return obj
+}
+
+// typeName names the type of a value in assignment errors.
+func typeName(obj object.Object) string {
+	if record, ok := obj.(*object.Record); ok {
+		return record.TypeName
+	}
+	return string(obj.Type())
+}
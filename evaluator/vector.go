@@ -0,0 +1,291 @@
+package evaluator
+
+import (
+	"interp/ast"
+	"interp/object"
+	"interp/token"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxVectorElements bounds the storage a single declaration may allocate.
+const maxVectorElements = 1 << 24
+
+func evalVectorDeclaration(
+	node *ast.DeclStatmentVector,
+	env *object.Environment,
+) object.Object {
+	rt := env.Runtime()
+	dims := make([]int, len(node.Dims))
+	size := 1
+	for i, dim := range node.Dims {
+		// a literal size would wrap around the word size like any other
+		// literal, and no index could reach the elements beyond it
+		if lit, ok := dim.(*ast.IntegerLiteral); ok && rt.Arithmetic != object.ARITH_BIG &&
+			(lit.Big != nil || lit.Value > rt.MaxInt()) {
+			return newError("size %s of vector %s out of range for %d-bit integers",
+				lit.Token.Literal, node.Name.Value, rt.WordSize)
+		}
+
+		val := Eval(dim, env)
+		if isError(val) {
+			return val
+		}
+
+		integer, ok := val.(*object.Integer)
+		if !ok || integer.Big != nil || integer.Value < 1 {
+			return newError("invalid size of vector %s: %s", node.Name.Value, val.Inspect())
+		}
+		if size > maxVectorElements/int(integer.Value) {
+			return newError("vector %s is too large: more than %d elements",
+				node.Name.Value, maxVectorElements)
+		}
+		dims[i] = int(integer.Value)
+		size *= dims[i]
+	}
+
+	zero := newValue(node.Type.Value, env)
+	if isError(zero) {
+		return zero
+	}
+
+	vector := &object.Vector{
+		ElementType: node.Type.Value,
+		Dims:        dims,
+		Elements:    make([]object.Object, size),
+	}
+	// basic values are immutable, so one zero serves all elements; records
+	// are changed in place and need one each
+	for i := range vector.Elements {
+		vector.Elements[i] = copyValue(zero)
+	}
+	env.Set(node.Name.Value, vector)
+
+	return NULL
+}
+
+func evalIndexExpression(
+	node *ast.IndexExpression,
+	env *object.Environment,
+) object.Object {
+	vector, offset, err := vectorElement(node, env)
+	if err != nil {
+		return err
+	}
+	if len(node.Indices) < len(vectorDims(vector)) {
+		return vectorRow(vector, len(node.Indices), offset)
+	}
+	return vector.Elements[offset]
+}
+
+// vectorRow returns the part of a vector selected by its first n indices,
+// starting at offset. The row shares the elements of the vector, so that
+// assigning to m[i][j] or reading into m[i] changes m itself.
+func vectorRow(v *object.Vector, n int, offset int) *object.Vector {
+	dims := vectorDims(v)[n:]
+	size := 1
+	for _, dim := range dims {
+		size *= dim
+	}
+	return &object.Vector{
+		ElementType: v.ElementType,
+		Dims:        dims,
+		Elements:    v.Elements[offset : offset+size : offset+size],
+	}
+}
+
+// vectorElement finds the element an index expression refers to, or the
+// first element of the row it selects when it has fewer indices than the
+// vector has dimensions. Indices start at 1 and are checked against every
+// dimension.
+func vectorElement(
+	node *ast.IndexExpression,
+	env *object.Environment,
+) (*object.Vector, int, object.Object) {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return nil, 0, left
+	}
+
+	vector, ok := left.(*object.Vector)
+	if !ok {
+		return nil, 0, newErrorAt(node.Token, "index operator not supported: %s", left.Type())
+	}
+
+	dims := vectorDims(vector)
+	if len(node.Indices) > len(dims) {
+		return nil, 0, newErrorAt(node.Token, "wrong number of indices for %s: want=%d, got=%d",
+			node.Left.String(), len(dims), len(node.Indices))
+	}
+
+	offset := 0
+	for i, index := range node.Indices {
+		val := Eval(index, env)
+		if isError(val) {
+			return nil, 0, val
+		}
+
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return nil, 0, newErrorAt(node.Token, "vector index must be INTEGER, got %s", val.Type())
+		}
+		if integer.Big != nil || integer.Value < 1 || integer.Value > int64(dims[i]) {
+			return nil, 0, newErrorAt(node.Token, "index %s out of bounds 1..%d in dimension %d of %s",
+				integer.Inspect(), dims[i], i+1, node.Left.String())
+		}
+
+		offset = offset*dims[i] + int(integer.Value) - 1
+	}
+	for _, dim := range dims[len(node.Indices):] {
+		offset *= dim
+	}
+
+	return vector, offset, nil
+}
+
+// assignTarget stores a copy of val in the variable, vector element or
+// record field target names.
+func assignTarget(target ast.Expression, val object.Object, env *object.Environment) object.Object {
+	switch target := target.(type) {
+	case *ast.IndexExpression:
+		vector, offset, err := vectorElement(target, env)
+		if err != nil {
+			return err
+		}
+		if len(target.Indices) < len(vectorDims(vector)) {
+			row := vectorRow(vector, len(target.Indices), offset)
+			return copyVector(target.Token, target.String(), row, val)
+		}
+		if narrows(vector.Elements[offset], val) {
+			return newErrorAt(target.Token, "type mismatch in assignment to %s: want %s, got %s",
+				target.String(), typeName(vector.Elements[offset]), typeName(val))
+		}
+		vector.Elements[offset] = widenLike(vector.Elements[offset], copyValue(val))
+
+	case *ast.FieldExpression:
+		record, err := recordField(target, env)
+		if err != nil {
+			return err
+		}
+		field := target.Field.Value
+		if narrows(record.Fields[field], val) {
+			return newErrorAt(target.Token, "type mismatch in assignment to %s: want %s, got %s",
+				target.String(), typeName(record.Fields[field]), typeName(val))
+		}
+		record.Fields[field] = widenLike(record.Fields[field], copyValue(val))
+
+	default:
+		current, _ := env.Get(target.TokenLiteral())
+		env.Assign(target.TokenLiteral(), widenLike(current, val))
+	}
+
+	return nil
+}
+
+// assignVariable stores val in the variable the statement names. Vectors
+// and records are copied, so the two variables stay independent; a vector
+// is copied element by element into a vector of the same shape.
+func assignVariable(node *ast.AssignStatement, val object.Object, env *object.Environment) object.Object {
+	name := node.Name.Value
+	current, _ := env.Get(name)
+
+	switch dst := current.(type) {
+	case nil:
+		env.Assign(name, val)
+
+	case *object.Vector:
+		return copyVector(node.Token, name, dst, val)
+
+	case *object.Record:
+		src, ok := val.(*object.Record)
+		if !ok || src.TypeName != dst.TypeName {
+			return newErrorAt(node.Token, "type mismatch in assignment to %s: want %s, got %s",
+				name, typeName(current), typeName(val))
+		}
+		env.Assign(name, copyValue(src))
+
+	default:
+		switch val.(type) {
+		case *object.Vector, *object.Record:
+			return newErrorAt(node.Token, "type mismatch in assignment to %s: want %s, got %s",
+				name, typeName(current), typeName(val))
+		}
+		if narrows(current, val) {
+			return newErrorAt(node.Token, "type mismatch in assignment to %s: want %s, got %s",
+				name, typeName(current), typeName(val))
+		}
+		env.Assign(name, widenLike(current, val))
+	}
+
+	return nil
+}
+
+// copyVector copies the elements of val into dst, a vector of the same
+// shape.
+func copyVector(tok token.Token, name string, dst *object.Vector, val object.Object) object.Object {
+	src, ok := val.(*object.Vector)
+	if !ok {
+		return newErrorAt(tok, "type mismatch in assignment to %s: want %s, got %s",
+			name, typeName(dst), typeName(val))
+	}
+	if !sameShape(dst, src) {
+		return newErrorAt(tok, "shape mismatch in assignment to %s: want %s, got %s",
+			name, vectorShape(dst), vectorShape(src))
+	}
+	for i, element := range src.Elements {
+		dst.Elements[i] = copyValue(element)
+	}
+	return nil
+}
+
+func sameShape(a, b *object.Vector) bool {
+	if a.ElementType != b.ElementType || len(a.Elements) != len(b.Elements) {
+		return false
+	}
+
+	adims, bdims := vectorDims(a), vectorDims(b)
+	if len(adims) != len(bdims) {
+		return false
+	}
+	for i := range adims {
+		if adims[i] != bdims[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func vectorDims(v *object.Vector) []int {
+	if len(v.Dims) == 0 {
+		return []int{len(v.Elements)}
+	}
+	return v.Dims
+}
+
+// vectorShape describes a vector the way it is declared.
+func vectorShape(v *object.Vector) string {
+	dims := []string{}
+	for _, dim := range vectorDims(v) {
+		dims = append(dims, strconv.Itoa(dim))
+	}
+	return "vector[" + strings.Join(dims, ", ") + "] of " + v.ElementType
+}
+
+// writeVector prints the elements separated by spaces, starting a new line
+// after each row of the last dimension.
+func writeVector(out io.Writer, v *object.Vector) {
+	dims := vectorDims(v)
+	row := dims[len(dims)-1]
+
+	for i, element := range v.Elements {
+		if i > 0 {
+			if i%row == 0 {
+				io.WriteString(out, "\n")
+			} else {
+				io.WriteString(out, " ")
+			}
+		}
+		io.WriteString(out, element.Inspect())
+	}
+}
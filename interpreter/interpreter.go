@@ -0,0 +1,165 @@
+package interpreter
+
+import (
+	"fmt"
+	"interp/ast"
+	"interp/checker"
+	"interp/evaluator"
+	"interp/lexer"
+	"interp/object"
+	"interp/parser"
+	"interp/token"
+	"io"
+	"time"
+)
+
+type Options struct {
+	Arithmetic object.ArithmeticMode
+	WordSize   int // 16, 32 or 64; zero selects 64
+
+	MaxCallDepth int // zero selects object.DefaultMaxCallDepth; at most object.MaxSafeCallDepth
+
+	IntegerDivide bool // "/" on two integers truncates, as div does
+
+	Seed int64 // seed of the random numbers of the first run; zero picks one from the clock
+
+	Stdin  io.Reader         // nil selects os.Stdin
+	Stdout io.Writer         // nil selects os.Stdout
+	Files  object.FileSystem // nil selects the host file system
+}
+
+// Interpreter runs programs against one global environment, so
+// declarations made by one Run are visible to the next.
+type Interpreter struct {
+	env     *object.Environment
+	checker *checker.Checker
+	types   []string // record types declared by earlier runs
+	seed    int64    // seed of the next run; later runs take theirs from the clock
+}
+
+type Result struct {
+	Value  object.Object
+	Errors []string // parser and checker errors; the program is not evaluated if any
+
+	Halted   bool // the program stopped by calling halt
+	ExitCode int  // the code passed to halt
+
+	Seed   int64 // seed of the random numbers, to replay the run with Options.Seed
+	Random bool  // the run drew random numbers, so replaying it needs Seed
+}
+
+func New(opts Options) (*Interpreter, error) {
+	rt := object.NewRuntime()
+	rt.Arithmetic = opts.Arithmetic
+
+	if opts.WordSize != 0 {
+		if !object.ValidWordSize(opts.WordSize) {
+			return nil, fmt.Errorf("unsupported word size %d", opts.WordSize)
+		}
+		rt.WordSize = opts.WordSize
+	}
+
+	if opts.MaxCallDepth < 0 {
+		return nil, fmt.Errorf("invalid call depth limit %d", opts.MaxCallDepth)
+	}
+	if opts.MaxCallDepth > object.MaxSafeCallDepth {
+		return nil, fmt.Errorf("call depth limit %d exceeds the maximum of %d",
+			opts.MaxCallDepth, object.MaxSafeCallDepth)
+	}
+	if opts.MaxCallDepth != 0 {
+		rt.MaxCallDepth = opts.MaxCallDepth
+	}
+
+	rt.IntegerDivide = opts.IntegerDivide
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	if opts.Stdin != nil {
+		rt.In = opts.Stdin
+	}
+	if opts.Stdout != nil {
+		rt.Out = opts.Stdout
+	}
+	if opts.Files != nil {
+		rt.Files = opts.Files
+	}
+
+	c := checker.New()
+	c.SetIntegerDivide(opts.IntegerDivide)
+
+	return &Interpreter{
+		env:     object.NewEnvironmentWithRuntime(rt),
+		checker: c,
+		seed:    seed,
+	}, nil
+}
+
+// Register makes a Go function callable from programs under name. See
+// evaluator.HostSignature for the supported parameter and result types.
+// Registering a name again replaces the earlier function.
+func (i *Interpreter) Register(name string, fn interface{}) error {
+	if tok := lexer.New(name).NextToken(); tok.Type != token.LEX_IDENT || tok.Literal != name {
+		return fmt.Errorf("%q is not a valid identifier", name)
+	}
+
+	params, result, err := evaluator.HostSignature(fn)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	builtin, err := evaluator.NewHostBuiltin(name, fn)
+	if err != nil {
+		return err
+	}
+
+	i.env.Set(name, builtin)
+	i.checker.DeclareBuiltin(name, params, result)
+	return nil
+}
+
+// Env returns the global environment, for embedders that inspect or set
+// program variables between runs.
+func (i *Interpreter) Env() *object.Environment {
+	return i.env
+}
+
+func (i *Interpreter) Run(input string) *Result {
+	l := lexer.New(input)
+	p := parser.New(l)
+	for _, name := range i.types {
+		p.DeclareType(name)
+	}
+
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return &Result{Errors: p.Errors()}
+	}
+
+	if errors := i.checker.Check(program); len(errors) != 0 {
+		return &Result{Errors: errors}
+	}
+
+	for _, st := range program.Statements {
+		if ts, ok := st.(*ast.TypeStatement); ok {
+			i.types = append(i.types, ts.Name.Value)
+		}
+	}
+
+	// every run starts from a seed of its own, so that it can be replayed
+	// alone in a new interpreter
+	rt := i.env.Runtime()
+	rt.SetSeed(i.seed)
+	i.seed = time.Now().UnixNano()
+
+	result := &Result{Value: evaluator.Eval(program, i.env), Seed: rt.Seed}
+	result.Random = rt.Drawn
+	if halt, ok := result.Value.(*object.Halt); ok {
+		result.Value = evaluator.NULL
+		result.Halted = true
+		result.ExitCode = halt.Code
+	}
+	return result
+}
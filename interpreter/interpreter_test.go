@@ -0,0 +1,354 @@
+package interpreter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"interp/object"
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	interp, err := New(Options{Arithmetic: object.ARITH_CHECKED, WordSize: 16})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	result := interp.Run("a: integer; a := 32767;")
+	if len(result.Errors) != 0 {
+		t.Fatalf("unexpected parser errors: %v", result.Errors)
+	}
+
+	result = interp.Run("a + 1;")
+	errObj, ok := result.Value.(*object.Error)
+	if !ok {
+		t.Fatalf("result is not Error. got=%T (%+v)", result.Value, result.Value)
+	}
+	if errObj.Message != "integer overflow: 32767 + 1" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+
+	if _, err := New(Options{WordSize: 8}); err == nil {
+		t.Errorf("expected error for 8-bit word size")
+	}
+
+	for _, tt := range []struct {
+		integerDivide bool
+		expected      string
+	}{
+		{false, "3.5"},
+		{true, "3"},
+	} {
+		interp, err := New(Options{IntegerDivide: tt.integerDivide})
+		if err != nil {
+			t.Fatalf("New returned error: %s", err)
+		}
+		result := interp.Run("7 / 2;")
+		if len(result.Errors) != 0 {
+			t.Fatalf("unexpected errors: %v", result.Errors)
+		}
+		if result.Value.Inspect() != tt.expected {
+			t.Errorf("wrong result of 7 / 2 with IntegerDivide=%t. got=%s, want=%s",
+				tt.integerDivide, result.Value.Inspect(), tt.expected)
+		}
+	}
+}
+
+func TestCallDepth(t *testing.T) {
+	if _, err := New(Options{MaxCallDepth: object.MaxSafeCallDepth + 1}); err == nil {
+		t.Errorf("expected error for call depth limit above %d", object.MaxSafeCallDepth)
+	}
+
+	interp, err := New(Options{MaxCallDepth: object.MaxSafeCallDepth})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	// Deeply nested statements make every call take more of the Go stack.
+	result := interp.Run(`function f(k: integer): integer;
+		begin
+			r: integer;
+			if k >= 0 then
+				if k >= 0 then
+					if k >= 0 then
+						while k < 0 do end;
+						r := f(k + 1);
+					end;
+				end;
+			end;
+			return r;
+		end;
+		f(0);`)
+	if len(result.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	errObj, ok := result.Value.(*object.Error)
+	if !ok {
+		t.Fatalf("result is not Error. got=%T (%+v)", result.Value, result.Value)
+	}
+	want := fmt.Sprintf("call depth limit of %d exceeded in f", object.MaxSafeCallDepth)
+	if errObj.Message != want {
+		t.Errorf("wrong error message. got=%q, want=%q", errObj.Message, want)
+	}
+}
+
+func TestStreams(t *testing.T) {
+	var out bytes.Buffer
+	interp, err := New(Options{Stdin: strings.NewReader("20 22"), Stdout: &out})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	result := interp.Run(`a, b: integer; read a, b; write "sum = ", a + b, skip;`)
+	if len(result.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+
+	if out.String() != "sum = 42\n" {
+		t.Errorf("wrong output. got=%q", out.String())
+	}
+}
+
+func TestFiles(t *testing.T) {
+	files := object.NewMemFileSystem()
+	files.WriteFile("in.txt", []byte("3 4\n5\n"))
+
+	interp, err := New(Options{Files: files})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	result := interp.Run(`f, g: file; n, sum: integer;
+assign(f, "in.txt"); reset(f);
+assign(g, "out.txt"); rewrite(g);
+while not eof(f) do read f, n; sum := sum + n; end;
+write g, "sum = ", sum, skip;
+close(f); close(g);`)
+	if len(result.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	if isError(result.Value) {
+		t.Fatalf("evaluation failed: %s", result.Value.Inspect())
+	}
+
+	data, ok := files.ReadFile("out.txt")
+	if !ok || string(data) != "sum = 12\n" {
+		t.Errorf("wrong contents of out.txt. got=%q, exists=%t", data, ok)
+	}
+}
+
+func TestHalt(t *testing.T) {
+	var out bytes.Buffer
+	interp, err := New(Options{Stdout: &out})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	result := interp.Run("write 1; halt(7); write 2;")
+	if len(result.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	if !result.Halted || result.ExitCode != 7 {
+		t.Errorf("wrong halt result. got Halted=%t, ExitCode=%d", result.Halted, result.ExitCode)
+	}
+	if out.String() != "1" {
+		t.Errorf("wrong output. got=%q", out.String())
+	}
+
+	result = interp.Run("write 3;")
+	if result.Halted || result.ExitCode != 0 {
+		t.Errorf("run after halt reports a halt. got Halted=%t, ExitCode=%d", result.Halted, result.ExitCode)
+	}
+}
+
+func TestSeed(t *testing.T) {
+	program := "write random(1000), space, random(1000); randomize(); write space, random(1000);"
+
+	run := func(seed int64) (string, int64) {
+		var out bytes.Buffer
+		interp, err := New(Options{Stdout: &out, Seed: seed})
+		if err != nil {
+			t.Fatalf("New returned error: %s", err)
+		}
+		result := interp.Run(program)
+		if len(result.Errors) != 0 {
+			t.Fatalf("unexpected errors: %v", result.Errors)
+		}
+		return out.String(), result.Seed
+	}
+
+	output, seed := run(0)
+	if seed == 0 {
+		t.Fatalf("run without a seed does not report the one picked")
+	}
+
+	replayed, replayedSeed := run(seed)
+	if replayed != output || replayedSeed != seed {
+		t.Errorf("replay with seed %d differs. got=%q (seed %d), want=%q",
+			seed, replayed, replayedSeed, output)
+	}
+
+	// a later run has a seed of its own, which replays it in a new interpreter
+	var out bytes.Buffer
+	interp, err := New(Options{Stdout: &out, Seed: seed})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+	if result := interp.Run("n: integer;"); result.Random {
+		t.Errorf("run without random numbers reports drawing them")
+	}
+	out.Reset()
+	result := interp.Run(program)
+	if !result.Random {
+		t.Errorf("run with random numbers does not report drawing them")
+	}
+	if result.Seed == seed {
+		t.Errorf("second run reuses the seed of the first")
+	}
+	if replayed, _ := run(result.Seed); replayed != out.String() {
+		t.Errorf("replay of second run with seed %d differs. got=%q, want=%q",
+			result.Seed, replayed, out.String())
+	}
+}
+
+func isError(obj object.Object) bool {
+	_, ok := obj.(*object.Error)
+	return ok
+}
+
+func TestRegister(t *testing.T) {
+	interp, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	var checked []int
+	register := map[string]interface{}{
+		"check": func(x int) bool {
+			checked = append(checked, x)
+			return x%2 == 0
+		},
+		"sum": func(v []int64) int64 {
+			var s int64
+			for _, x := range v {
+				s += x
+			}
+			return s
+		},
+		"scale": func(v []float64, k float64) []float64 {
+			for i := range v {
+				v[i] *= k
+			}
+			return v
+		},
+		"half": func(x int) (int, error) {
+			if x%2 != 0 {
+				return 0, errors.New("odd input")
+			}
+			return x / 2, nil
+		},
+		"greet": func(name string) string { return "hello, " + name },
+	}
+	for name, fn := range register {
+		if err := interp.Register(name, fn); err != nil {
+			t.Fatalf("Register(%q) returned error: %s", name, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"check(4);", "1"},
+		{"check(3) + check(2);", "1"},
+		{"v: vector[3] of integer; sum(v);", "0"},
+		{"r: vector[2] of real; scale(r, 2.0);", "[0.0, 0.0]"},
+		{"half(8) + 1;", "5"},
+		{`greet("grader");`, "hello, grader"},
+		{"half(7);", "ERROR: line 1, column 1: half: odd input"},
+		{"check(99999999999999999999);", "ERROR: integer literal 99999999999999999999 out of range for 64-bit integers"},
+	}
+
+	for _, tt := range tests {
+		result := interp.Run(tt.input)
+		if len(result.Errors) != 0 {
+			t.Fatalf("unexpected errors for %q: %v", tt.input, result.Errors)
+		}
+		if result.Value.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. got=%s, want=%s",
+				tt.input, result.Value.Inspect(), tt.expected)
+		}
+	}
+
+	if len(checked) != 3 || checked[0] != 4 || checked[1] != 3 || checked[2] != 2 {
+		t.Errorf("check recorded wrong values. got=%v", checked)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"check(1, 2);", "wrong number of arguments to check: want=1, got=2"},
+		{`sum("x");`, "argument 1 of sum must be vector, got string"},
+		{"check(1.5);", "argument 1 of check must be integer, got real"},
+	}
+
+	for _, tt := range errorTests {
+		result := interp.Run(tt.input)
+		if len(result.Errors) != 1 || result.Errors[0] != tt.expected {
+			t.Errorf("wrong errors for %q. got=%v, want=%q", tt.input, result.Errors, tt.expected)
+		}
+	}
+
+	badRegistrations := []struct {
+		name string
+		fn   interface{}
+	}{
+		{"notfunc", 42},
+		{"variadic", func(xs ...int) int { return 0 }},
+		{"pair", func() (int, int) { return 0, 0 }},
+		{"mapped", func(m map[string]int) {}},
+		{"read", func() {}},
+		{"two words", func() {}},
+	}
+
+	for _, tt := range badRegistrations {
+		if err := interp.Register(tt.name, tt.fn); err == nil {
+			t.Errorf("expected error registering %q", tt.name)
+		}
+	}
+}
+
+func TestEnv(t *testing.T) {
+	interp, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	interp.Run("a: integer; a := 41;")
+	interp.Env().Assign("a", &object.Integer{Value: 42})
+
+	result := interp.Run("a;")
+	if result.Value.Inspect() != "42" {
+		t.Errorf("a has wrong value. got=%s", result.Value.Inspect())
+	}
+}
+
+func TestRecordTypesAcrossRuns(t *testing.T) {
+	interp, err := New(Options{})
+	if err != nil {
+		t.Fatalf("New returned error: %s", err)
+	}
+
+	for _, input := range []string{"type point = record x, y: real end;", "p: point;", "p.y := 2.5;"} {
+		if result := interp.Run(input); len(result.Errors) != 0 {
+			t.Fatalf("unexpected errors for %q: %v", input, result.Errors)
+		}
+	}
+
+	result := interp.Run("p;")
+	if result.Value.Inspect() != "point(x: 0.0, y: 2.5)" {
+		t.Errorf("p has wrong value. got=%s", result.Value.Inspect())
+	}
+}
@@ -1,21 +1,32 @@
 package lexer
 
-import "interp/token"
+import (
+	"interp/token"
+	"unicode/utf8"
+)
 
 type Lexer struct {
 	input        string
 	position     int  // current position in input (points to current char)
 	readPosition int  // current reading position in input (after current char)
 	ch           byte // current char under examination
+	line         int  // line of ch, starting at 1
+	column       int  // column of ch, starting at 1
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar()
 	return l
 }
 
 func (l *Lexer) readChar() {
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
@@ -26,10 +37,17 @@ func (l *Lexer) readChar() {
 }
 
 func (l *Lexer) NextToken() token.Token {
-	var tok token.Token
-
 	l.skipWhitespace()
 
+	line, column := l.line, l.column
+	tok := l.readToken()
+	tok.Line, tok.Column = line, column
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
+	var tok token.Token
+
 	switch l.ch {
 	case '[':
 		tok = newToken(token.LEX_LBRACKET, l.ch)
@@ -74,6 +92,23 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.LEX_COLON, l.ch)
 		}
+	case '.':
+		if isDigit(l.peekChar()) {
+			return l.readNumber()
+		}
+		tok = newToken(token.LEX_DOT, l.ch)
+	case '"':
+		if str, ok := l.readString(); ok {
+			tok = token.Token{Type: token.LEX_STR, Literal: str}
+		} else {
+			tok = token.Token{Type: token.LEX_ILLEGAL, Literal: str}
+		}
+	case '\'':
+		if ch, ok := l.readCharLiteral(); ok {
+			tok = token.Token{Type: token.LEX_CHR, Literal: ch}
+		} else {
+			tok = token.Token{Type: token.LEX_ILLEGAL, Literal: ch}
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.LEX_EOF
@@ -85,9 +120,7 @@ func (l *Lexer) NextToken() token.Token {
 			return tok
 
 		} else if isDigit(l.ch) {
-			tok.Type = token.LEX_INT
-			tok.Literal = l.readNumber()
-			return tok
+			return l.readNumber()
 		} else {
 			tok = newToken(token.LEX_ILLEGAL, l.ch)
 		}
@@ -97,14 +130,154 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer in any of the suffixed forms or a real
+// number: 3.14, 5., .25, 1.5E+10, 2e-3.
+func (l *Lexer) readNumber() token.Token {
 	position := l.position
+	for isDigit(l.ch) {
+		l.readChar()
+	}
+
+	if l.ch == '.' {
+		l.readChar()
+		for isDigit(l.ch) {
+			l.readChar()
+		}
+		l.readExponent()
+		return token.Token{Type: token.LEX_FLOAT, Literal: l.input[position:l.position]}
+	}
+
+	if (l.ch == 'E' || l.ch == 'e') && l.exponentFollows() {
+		l.readExponent()
+		return token.Token{Type: token.LEX_FLOAT, Literal: l.input[position:l.position]}
+	}
+
 	for isDigit(l.ch) || l.ch == 'A' || l.ch == 'a' || l.ch == 'B' || l.ch == 'b' || l.ch == 'C' ||
 		l.ch == 'c' || l.ch == 'H' || l.ch == 'h' || l.ch == 'D' || l.ch == 'd' || l.ch == 'E' || l.ch == 'e' || l.ch == 'F' || l.ch == 'f' {
 
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return token.Token{Type: token.LEX_INT, Literal: l.input[position:l.position]}
+}
+
+// exponentFollows reports whether the E at the current position starts an
+// exponent rather than a hexadecimal digit: the exponent digits must run up
+// to the end of the number, so 1E5 is real while 1E5H and 1EH are not.
+func (l *Lexer) exponentFollows() bool {
+	i := l.readPosition
+	if i < len(l.input) && (l.input[i] == '+' || l.input[i] == '-') {
+		i++
+	}
+
+	start := i
+	for i < len(l.input) && isDigit(l.input[i]) {
+		i++
+	}
+
+	return i > start && (i == len(l.input) || !isLetter(l.input[i]))
+}
+
+func (l *Lexer) readExponent() {
+	if (l.ch != 'E' && l.ch != 'e') || !l.exponentFollows() {
+		return
+	}
+
+	l.readChar()
+	if l.ch == '+' || l.ch == '-' {
+		l.readChar()
+	}
+	for isDigit(l.ch) {
+		l.readChar()
+	}
+}
+
+// readString reads a quoted string and returns its contents with the
+// escapes \n, \t, \" and \\ replaced. It reports false for an unknown
+// escape or a string that is not closed before the end of the line.
+func (l *Lexer) readString() (string, bool) {
+	var out []byte
+
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '"':
+			return string(out), true
+		case 0, '\n':
+			return string(out), false
+		case '\\':
+			l.readChar()
+			switch l.ch {
+			case 'n':
+				out = append(out, '\n')
+			case 't':
+				out = append(out, '\t')
+			case '"', '\\':
+				out = append(out, l.ch)
+			default:
+				return string(out), false
+			}
+		default:
+			out = append(out, l.ch)
+		}
+	}
+}
+
+// readCharLiteral reads one character up to the closing quote. The escapes
+// are those of strings, with \' in place of \"; a quote may also be written
+// twice, as in Pascal's ''''. A malformed literal is skipped up to its
+// closing quote and its text returned.
+func (l *Lexer) readCharLiteral() (string, bool) {
+	start := l.position
+	l.readChar()
+
+	var out string
+	switch l.ch {
+	case '\'':
+		if l.peekChar() != '\'' {
+			return l.skipCharLiteral(start), false
+		}
+		l.readChar()
+		out = "'"
+	case 0, '\n':
+		return l.skipCharLiteral(start), false
+	case '\\':
+		l.readChar()
+		switch l.ch {
+		case 'n':
+			out = "\n"
+		case 't':
+			out = "\t"
+		case '\'', '\\':
+			out = string(l.ch)
+		default:
+			return l.skipCharLiteral(start), false
+		}
+	default:
+		r, size := utf8.DecodeRuneInString(l.input[l.position:])
+		out = string(r)
+		for i := 1; i < size; i++ {
+			l.readChar()
+		}
+	}
+
+	l.readChar()
+	if l.ch != '\'' {
+		return l.skipCharLiteral(start), false
+	}
+	return out, true
+}
+
+// skipCharLiteral moves to the quote that closes a malformed character
+// literal, or to the end of the line, and returns the literal's text.
+func (l *Lexer) skipCharLiteral(start int) string {
+	for l.ch != '\'' && l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+	if l.ch == '\'' {
+		return l.input[start : l.position+1]
+	}
+	return l.input[start:l.position]
 }
 
 func isDigit(ch byte) bool {
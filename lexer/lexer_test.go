@@ -26,7 +26,18 @@ func TestNextToken(t *testing.T) {
 	read skip space tab;
 	>,=<><=>=<*/
 	1343456B, 1343456b, 1343456C, 1343456c, 1343456D, 1343456H, 5ABH of
-	arr: vector[10] of integer;`
+	arr: vector[10] of integer;
+	exit when
+	while do repeat until for to downto
+	and or not
+	procedure var
+	function return
+	"sum = " "a\tb\n" "q\"\\" string
+	case const
+	type record p.x a[1].y
+	char 'a' '\n' '\'' 'é' '' 'ab' ''''
+	3.14 5. .25 1.5E+10 2e-3 1E5 12EH 1E5H
+	"open`
 
 	tests := []struct {
 		expectedType    token.TokenType
@@ -112,6 +123,56 @@ func TestNextToken(t *testing.T) {
 		{token.KW_OF, "of"},
 		{token.KW_INTEGER, "integer"},
 		{token.LEX_SEMICOLON, ";"},
+		{token.KW_EXIT, "exit"},
+		{token.KW_WHEN, "when"},
+		{token.KW_WHILE, "while"},
+		{token.KW_DO, "do"},
+		{token.KW_REPEAT, "repeat"},
+		{token.KW_UNTIL, "until"},
+		{token.KW_FOR, "for"},
+		{token.KW_TO, "to"},
+		{token.KW_DOWNTO, "downto"},
+		{token.KW_AND, "and"},
+		{token.KW_OR, "or"},
+		{token.KW_NOT, "not"},
+		{token.KW_PROCEDURE, "procedure"},
+		{token.KW_VAR, "var"},
+		{token.KW_FUNCTION, "function"},
+		{token.KW_RETURN, "return"},
+		{token.LEX_STR, "sum = "},
+		{token.LEX_STR, "a\tb\n"},
+		{token.LEX_STR, "q\"\\"},
+		{token.KW_STRING, "string"},
+		{token.KW_CASE, "case"},
+		{token.KW_CONST, "const"},
+		{token.KW_TYPE, "type"},
+		{token.KW_RECORD, "record"},
+		{token.LEX_IDENT, "p"},
+		{token.LEX_DOT, "."},
+		{token.LEX_IDENT, "x"},
+		{token.LEX_IDENT, "a"},
+		{token.LEX_LBRACKET, "["},
+		{token.LEX_INT, "1"},
+		{token.LEX_RBRACKET, "]"},
+		{token.LEX_DOT, "."},
+		{token.LEX_IDENT, "y"},
+		{token.KW_CHAR, "char"},
+		{token.LEX_CHR, "a"},
+		{token.LEX_CHR, "\n"},
+		{token.LEX_CHR, "'"},
+		{token.LEX_CHR, "é"},
+		{token.LEX_ILLEGAL, "''"},
+		{token.LEX_ILLEGAL, "'ab'"},
+		{token.LEX_CHR, "'"},
+		{token.LEX_FLOAT, "3.14"},
+		{token.LEX_FLOAT, "5."},
+		{token.LEX_FLOAT, ".25"},
+		{token.LEX_FLOAT, "1.5E+10"},
+		{token.LEX_FLOAT, "2e-3"},
+		{token.LEX_FLOAT, "1E5"},
+		{token.LEX_INT, "12EH"},
+		{token.LEX_INT, "1E5H"},
+		{token.LEX_ILLEGAL, "open"},
 		{token.LEX_EOF, ""},
 	}
 
@@ -130,3 +191,42 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "x := 1;\n  y := sqrt(x);\n"
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"x", 1, 1},
+		{":=", 1, 3},
+		{"1", 1, 6},
+		{";", 1, 7},
+		{"y", 2, 3},
+		{":=", 2, 5},
+		{"sqrt", 2, 8},
+		{"(", 2, 12},
+		{"x", 2, 13},
+		{")", 2, 14},
+		{";", 2, 15},
+		{"", 3, 1},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Line != tt.expectedLine || tok.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position of %q wrong. expected=%d:%d, got=%d:%d",
+				i, tok.Literal, tt.expectedLine, tt.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
@@ -1,10 +1,74 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"interp/interpreter"
+	"interp/object"
 	"interp/repl"
 	"os"
 )
 
 func main() {
-	repl.Start(os.Stdin, os.Stdout)
+	arith := flag.String("arith", "wrap", "integer overflow handling: wrap, checked or big")
+	wordSize := flag.Int("word", 64, "integer word size in bits: 16, 32 or 64")
+	depth := flag.Int("depth", object.DefaultMaxCallDepth,
+		fmt.Sprintf("maximum depth of nested procedure and function calls, at most %d", object.MaxSafeCallDepth))
+	intDiv := flag.Bool("intdiv", false, "make / on two integers truncate like div, as older programs expect")
+	seed := flag.Int64("seed", 0, "seed of the random numbers, to replay a run; 0 picks one from the clock")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [program]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	mode, err := object.ParseArithmeticMode(*arith)
+	if err != nil {
+		fail(err)
+	}
+
+	interp, err := interpreter.New(interpreter.Options{
+		Arithmetic:    mode,
+		WordSize:      *wordSize,
+		MaxCallDepth:  *depth,
+		IntegerDivide: *intDiv,
+		Seed:          *seed,
+	})
+	if err != nil {
+		fail(err)
+	}
+
+	if flag.NArg() == 0 {
+		os.Exit(repl.Start(os.Stdin, os.Stdout, interp))
+	}
+
+	source, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fail(err)
+	}
+
+	result := interp.Run(string(source))
+	if len(result.Errors) != 0 {
+		for _, msg := range result.Errors {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		os.Exit(1)
+	}
+
+	// a failed run that drew random numbers reports its seed, so that it
+	// can be replayed exactly
+	code := result.ExitCode
+	if errObj, ok := result.Value.(*object.Error); ok {
+		fmt.Fprintln(os.Stderr, errObj.Inspect())
+		code = 1
+	}
+	if code != 0 && result.Random {
+		fmt.Fprintf(os.Stderr, "replay with -seed %d\n", result.Seed)
+	}
+	os.Exit(code)
+}
+
+func fail(v interface{}) {
+	fmt.Fprintln(os.Stderr, v)
+	os.Exit(1)
 }
@@ -1,33 +1,63 @@
 package object
 
 type Environment struct {
-	store map[string]Object
-	Marks map[string]int
-	outer *Environment
+	store   map[string]Object
+	outer   *Environment
+	runtime *Runtime
 }
 
 func NewEnvironment() *Environment {
+	return NewEnvironmentWithRuntime(NewRuntime())
+}
+
+func NewEnvironmentWithRuntime(rt *Runtime) *Environment {
 	return &Environment{
-		store: make(map[string]Object),
-		Marks: make(map[string]int),
-		outer: nil,
+		store:   make(map[string]Object),
+		outer:   nil,
+		runtime: rt,
 	}
 }
 
 func NewEnclosedEnvironment(outer *Environment) *Environment {
-	env := NewEnvironment()
+	env := NewEnvironmentWithRuntime(outer.runtime)
 	env.outer = outer
 	return env
 }
 
+func (e *Environment) Runtime() *Runtime {
+	return e.runtime
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
 	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+		return e.outer.Get(name)
+	}
+	if ref, isRef := obj.(*Reference); isRef {
+		return ref.Env.Get(ref.Name)
 	}
 	return obj, ok
 }
 
+// Assign updates a variable in the environment that declares it, writing
+// through var parameters. Undeclared names are created in e.
+func (e *Environment) Assign(name string, val Object) Object {
+	for env := e; env != nil; env = env.outer {
+		obj, ok := env.store[name]
+		if !ok {
+			continue
+		}
+
+		if ref, isRef := obj.(*Reference); isRef {
+			return ref.Env.Assign(ref.Name, val)
+		}
+		env.store[name] = val
+		return val
+	}
+
+	return e.Set(name, val)
+}
+
 func (e *Environment) Set(name string, val Object) Object {
 	e.store[name] = val
 	return val
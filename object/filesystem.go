@@ -0,0 +1,72 @@
+package object
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// FileSystem opens the files programs name with assign. Embedders replace
+// the host file system to sandbox programs or to feed them test data.
+type FileSystem interface {
+	Open(name string) (io.ReadCloser, error)
+	Create(name string) (io.WriteCloser, error)
+}
+
+// HostFileSystem is the file system of the machine running the program.
+type HostFileSystem struct{}
+
+func (HostFileSystem) Open(name string) (io.ReadCloser, error)    { return os.Open(name) }
+func (HostFileSystem) Create(name string) (io.WriteCloser, error) { return os.Create(name) }
+
+// MemFileSystem keeps files in memory. A file written by a program becomes
+// visible when it is closed.
+type MemFileSystem struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func NewMemFileSystem() *MemFileSystem {
+	return &MemFileSystem{files: make(map[string][]byte)}
+}
+
+// WriteFile stores a file, replacing any file of that name.
+func (m *MemFileSystem) WriteFile(name string, data []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = bytes.Clone(data)
+}
+
+// ReadFile returns the contents of a file and whether it exists.
+func (m *MemFileSystem) ReadFile(name string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	return bytes.Clone(data), ok
+}
+
+func (m *MemFileSystem) Open(name string) (io.ReadCloser, error) {
+	data, ok := m.ReadFile(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *MemFileSystem) Create(name string) (io.WriteCloser, error) {
+	m.WriteFile(name, nil)
+	return &memFile{fs: m, name: name}, nil
+}
+
+type memFile struct {
+	bytes.Buffer
+	fs   *MemFileSystem
+	name string
+}
+
+func (f *memFile) Close() error {
+	f.fs.WriteFile(f.name, f.Bytes())
+	return nil
+}
@@ -1,6 +1,15 @@
 package object
 
-import "fmt"
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"interp/ast"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+)
 
 type ObjectType string
 
@@ -9,7 +18,22 @@ const (
 	ERROR_OBJ = "ERROR"
 
 	INTEGER_OBJ = "INTEGER"
+	REAL_OBJ    = "REAL"
+	STRING_OBJ  = "STRING"
+	CHAR_OBJ    = "CHAR"
+	VECTOR_OBJ  = "VECTOR"
+	RECORD_OBJ  = "RECORD"
+	FILE_OBJ    = "FILE"
 	GOTO_OBJ    = "GOTO"
+	EXIT_OBJ    = "EXIT"
+	HALT_OBJ    = "HALT"
+
+	PROCEDURE_OBJ    = "PROCEDURE"
+	FUNCTION_OBJ     = "FUNCTION"
+	BUILTIN_OBJ      = "BUILTIN"
+	RECORD_TYPE_OBJ  = "RECORD_TYPE"
+	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	REFERENCE_OBJ    = "REFERENCE"
 )
 
 type Object interface {
@@ -19,10 +43,152 @@ type Object interface {
 
 type Integer struct {
 	Value int64
+	Big   *big.Int // set instead of Value when the number does not fit in int64
 }
 
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
-func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
+func (i *Integer) Inspect() string {
+	if i.Big != nil {
+		return i.Big.String()
+	}
+	return fmt.Sprintf("%d", i.Value)
+}
+
+// BigValue returns the number as a big.Int whichever way it is stored.
+func (i *Integer) BigValue() *big.Int {
+	if i.Big != nil {
+		return i.Big
+	}
+	return big.NewInt(i.Value)
+}
+
+// NewBigInteger keeps values that fit in int64 on the fast path.
+func NewBigInteger(value *big.Int) *Integer {
+	if value.IsInt64() {
+		return &Integer{Value: value.Int64()}
+	}
+	return &Integer{Big: value}
+}
+
+type Real struct {
+	Value float64
+}
+
+func (r *Real) Type() ObjectType { return REAL_OBJ }
+
+// Inspect always shows a decimal point or an exponent, so reals can be told
+// apart from integers.
+func (r *Real) Inspect() string {
+	s := strconv.FormatFloat(r.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+
+type String struct {
+	Value string
+}
+
+func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) Inspect() string  { return s.Value }
+
+type Char struct {
+	Value rune
+}
+
+func (c *Char) Type() ObjectType { return CHAR_OBJ }
+func (c *Char) Inspect() string  { return string(c.Value) }
+
+// Vector stores its elements in row-major order: the last index varies
+// fastest.
+type Vector struct {
+	ElementType string // declared element type: a basic type or a record type
+	Dims        []int  // size of each dimension
+	Elements    []Object
+}
+
+func (v *Vector) Type() ObjectType { return VECTOR_OBJ }
+func (v *Vector) Inspect() string {
+	var out bytes.Buffer
+
+	dims := v.Dims
+	if len(dims) == 0 {
+		dims = []int{len(v.Elements)}
+	}
+	v.inspect(&out, dims, v.Elements)
+
+	return out.String()
+}
+
+// inspect writes elements as nested lists, one level per dimension.
+func (v *Vector) inspect(out *bytes.Buffer, dims []int, elements []Object) {
+	out.WriteString("[")
+	if len(dims) == 1 {
+		for i, e := range elements {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(e.Inspect())
+		}
+	} else if dims[0] > 0 {
+		stride := len(elements) / dims[0]
+		for i := 0; i < dims[0]; i++ {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			v.inspect(out, dims[1:], elements[i*stride:(i+1)*stride])
+		}
+	}
+	out.WriteString("]")
+}
+
+// Record is a value of a record type; Names keeps the declared field order.
+type Record struct {
+	TypeName string
+	Names    []string
+	Fields   map[string]Object
+}
+
+func (r *Record) Type() ObjectType { return RECORD_OBJ }
+func (r *Record) Inspect() string {
+	var out bytes.Buffer
+
+	fields := []string{}
+	for _, name := range r.Names {
+		fields = append(fields, name+": "+r.Fields[name].Inspect())
+	}
+
+	out.WriteString(r.TypeName)
+	out.WriteString("(")
+	out.WriteString(strings.Join(fields, ", "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+// RecordType is bound to the name of a type statement. Env is where the
+// sizes of vector fields are looked up.
+type RecordType struct {
+	Name   string
+	Fields []ast.Statement
+	Env    *Environment
+}
+
+func (rt *RecordType) Type() ObjectType { return RECORD_TYPE_OBJ }
+func (rt *RecordType) Inspect() string  { return "type " + rt.Name }
+
+// File is the value of a file variable. assign names the file, reset opens
+// it for reading, rewrite for writing, and close releases it again.
+type File struct {
+	Name   string
+	In     *bufio.Reader // set while open for reading
+	Out    io.Writer     // set while open for writing
+	Closer io.Closer     // the open file, nil if closed
+}
+
+func (f *File) Type() ObjectType { return FILE_OBJ }
+func (f *File) Inspect() string  { return "file " + strconv.Quote(f.Name) }
 
 type Null struct{}
 
@@ -42,3 +208,96 @@ type Goto struct {
 
 func (gt *Goto) Type() ObjectType { return GOTO_OBJ }
 func (gt *Goto) Inspect() string  { return fmt.Sprintf("%s", gt.Mark) }
+
+type Exit struct{}
+
+func (ex *Exit) Type() ObjectType { return EXIT_OBJ }
+func (ex *Exit) Inspect() string  { return "exit" }
+
+// Halt stops the program with an exit code. It unwinds everything, like an
+// error does.
+type Halt struct {
+	Code int
+}
+
+func (h *Halt) Type() ObjectType { return HALT_OBJ }
+func (h *Halt) Inspect() string  { return fmt.Sprintf("halt(%d)", h.Code) }
+
+type Procedure struct {
+	Name       string
+	Parameters []*ast.Parameter
+	Body       *ast.BeginExpression
+	Env        *Environment
+}
+
+func (p *Procedure) Type() ObjectType { return PROCEDURE_OBJ }
+func (p *Procedure) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range p.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("procedure ")
+	out.WriteString(p.Name)
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, "; "))
+	out.WriteString(")")
+
+	return out.String()
+}
+
+type Function struct {
+	Name       string
+	Parameters []*ast.Parameter
+	ReturnType string
+	Body       *ast.BeginExpression
+	Env        *Environment
+}
+
+func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
+func (f *Function) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range f.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString("function ")
+	out.WriteString(f.Name)
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, "; "))
+	out.WriteString("): ")
+	out.WriteString(f.ReturnType)
+
+	return out.String()
+}
+
+type BuiltinFunction func(rt *Runtime, args ...Object) Object
+
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin function " + b.Name }
+
+type ReturnValue struct {
+	Value Object
+}
+
+func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
+func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
+
+// Reference is bound in a procedure frame for a var parameter and stands
+// for the caller's variable; Environment reads and writes through it.
+type Reference struct {
+	Env  *Environment
+	Name string
+}
+
+func (r *Reference) Type() ObjectType { return REFERENCE_OBJ }
+func (r *Reference) Inspect() string  { return "var " + r.Name }
@@ -0,0 +1,102 @@
+package object
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+)
+
+type ArithmeticMode int
+
+const (
+	ARITH_WRAP    ArithmeticMode = iota // overflow silently wraps around
+	ARITH_CHECKED                       // overflow is a runtime error
+	ARITH_BIG                           // integers grow without bound
+)
+
+var arithmeticModes = map[string]ArithmeticMode{
+	"wrap":    ARITH_WRAP,
+	"checked": ARITH_CHECKED,
+	"big":     ARITH_BIG,
+}
+
+func ParseArithmeticMode(name string) (ArithmeticMode, error) {
+	if mode, ok := arithmeticModes[name]; ok {
+		return mode, nil
+	}
+	return ARITH_WRAP, fmt.Errorf("unknown arithmetic mode %q", name)
+}
+
+func (m ArithmeticMode) String() string {
+	for name, mode := range arithmeticModes {
+		if mode == m {
+			return name
+		}
+	}
+	return fmt.Sprintf("ArithmeticMode(%d)", int(m))
+}
+
+// MaxSafeCallDepth is the largest call depth limit accepted. Every nested
+// call takes Go stack for each level of statement nesting in its body, and
+// much deeper recursion can exhaust the stack before the limit is reached.
+const MaxSafeCallDepth = 10000
+
+const DefaultMaxCallDepth = MaxSafeCallDepth
+
+// Runtime holds the settings and state shared by every environment of one
+// program.
+type Runtime struct {
+	Arithmetic ArithmeticMode
+	WordSize   int // integer width in bits: 16, 32 or 64; unused by ARITH_BIG
+
+	MaxCallDepth int // nested procedure and function calls allowed
+	CallDepth    int
+
+	IntegerDivide bool // "/" on two integers truncates like div instead of giving a real
+
+	In    io.Reader  // read statements scan from here
+	Out   io.Writer  // write statements print here
+	Files FileSystem // files named by assign are opened here
+
+	Seed  int64      // every random number of the program derives from it
+	Drawn bool       // random was called since the seed was set
+	Rand  *rand.Rand // random draws from here
+	seeds *rand.Rand // randomize takes new seeds for Rand from here
+}
+
+func NewRuntime() *Runtime {
+	rt := &Runtime{
+		Arithmetic:   ARITH_WRAP,
+		WordSize:     64,
+		MaxCallDepth: DefaultMaxCallDepth,
+		In:           os.Stdin,
+		Out:          os.Stdout,
+		Files:        HostFileSystem{},
+	}
+	rt.SetSeed(time.Now().UnixNano())
+	return rt
+}
+
+// SetSeed restarts the random numbers from seed. Running a program again
+// with the same seed gives the same numbers, randomize included.
+func (rt *Runtime) SetSeed(seed int64) {
+	rt.Seed = seed
+	rt.Drawn = false
+	rt.seeds = rand.New(rand.NewSource(seed))
+	rt.Randomize()
+}
+
+// Randomize starts a new sequence of random numbers.
+func (rt *Runtime) Randomize() {
+	rt.Rand = rand.New(rand.NewSource(rt.seeds.Int63()))
+}
+
+func ValidWordSize(bits int) bool {
+	return bits == 16 || bits == 32 || bits == 64
+}
+
+// MinInt and MaxInt return the integer range of the configured word size.
+func (rt *Runtime) MinInt() int64 { return -1 << (rt.WordSize - 1) }
+func (rt *Runtime) MaxInt() int64 { return 1<<(rt.WordSize-1) - 1 }
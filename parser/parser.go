@@ -5,29 +5,44 @@ import (
 	"interp/ast"
 	"interp/lexer"
 	"interp/token"
+	"math/big"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 const (
 	_ int = iota
 	LOWEST
+	OR          // or
+	AND         // and
+	NOT         // not X
 	EQUALS      // ==
 	LESSGREATER // > or <
 	SUM         // +
 	PRODUCT     // *
 	PREFIX      // -X or !X
 	CALL        // myFunction(X)
+	INDEX       // vector[index]
 )
 
 var precedences = map[token.TokenType]int{
-	token.LEX_EQ:   EQUALS,
-	token.LEX_NE:   EQUALS,
-	token.LEX_LT:   LESSGREATER,
-	token.LEX_GT:   LESSGREATER,
-	token.LEX_PLUS: SUM,
-	token.LEX_MIN:  SUM,
-	token.LEX_MULT: PRODUCT,
-	token.LEX_DIV:  PRODUCT,
+	token.KW_OR:        OR,
+	token.KW_AND:       AND,
+	token.LEX_EQ:       EQUALS,
+	token.LEX_NE:       EQUALS,
+	token.LEX_LT:       LESSGREATER,
+	token.LEX_GT:       LESSGREATER,
+	token.LEX_LE:       LESSGREATER,
+	token.LEX_GE:       LESSGREATER,
+	token.LEX_PLUS:     SUM,
+	token.LEX_MIN:      SUM,
+	token.LEX_MULT:     PRODUCT,
+	token.LEX_DIV:      PRODUCT,
+	token.KW_DIV:       PRODUCT,
+	token.LEX_LPAREN:   CALL,
+	token.LEX_LBRACKET: INDEX,
+	token.LEX_DOT:      INDEX,
 }
 
 type (
@@ -44,35 +59,55 @@ type Parser struct {
 
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+
+	types map[string]bool // record types declared so far
 }
 
 func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:      l,
 		errors: []string{},
+		types:  make(map[string]bool),
 	}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.LEX_IDENT, p.parseIdentifier)
 	p.registerPrefix(token.LEX_INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.LEX_MIN, p.parsePrefixExpression)
+	p.registerPrefix(token.KW_NOT, p.parsePrefixExpression)
 	p.registerPrefix(token.LEX_LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.KW_IF, p.parseIfExpression)
 	p.registerPrefix(token.KW_BEGIN, p.parseBeginExpression)
 	p.registerPrefix(token.KW_LOOP, p.parseLoopExpression)
 	p.registerPrefix(token.KW_READ, p.parseReadExpression)
+	p.registerPrefix(token.KW_WRITE, p.parseWriteExpression)
+	p.registerPrefix(token.LEX_STR, p.parseStringLiteral)
+	p.registerPrefix(token.LEX_CHR, p.parseCharLiteral)
+	p.registerPrefix(token.LEX_ILLEGAL, p.parseIllegal)
+	p.registerPrefix(token.KW_REAL, p.parseConversion)
+	p.registerPrefix(token.LEX_FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.KW_WHILE, p.parseWhileExpression)
+	p.registerPrefix(token.KW_REPEAT, p.parseRepeatExpression)
+	p.registerPrefix(token.KW_FOR, p.parseForExpression)
+	p.registerPrefix(token.KW_CASE, p.parseCaseExpression)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.LEX_PLUS, p.parseInfixExpression)
 	p.registerInfix(token.LEX_MIN, p.parseInfixExpression)
 	p.registerInfix(token.LEX_MULT, p.parseInfixExpression)
 	p.registerInfix(token.LEX_DIV, p.parseInfixExpression)
+	p.registerInfix(token.KW_DIV, p.parseInfixExpression)
 	p.registerInfix(token.LEX_GT, p.parseInfixExpression)
 	p.registerInfix(token.LEX_LT, p.parseInfixExpression)
 	p.registerInfix(token.LEX_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LEX_NE, p.parseInfixExpression)
 	p.registerInfix(token.LEX_LE, p.parseInfixExpression)
 	p.registerInfix(token.LEX_GE, p.parseInfixExpression)
+	p.registerInfix(token.KW_AND, p.parseInfixExpression)
+	p.registerInfix(token.KW_OR, p.parseInfixExpression)
+	p.registerInfix(token.LEX_LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LEX_LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.LEX_DOT, p.parseFieldExpression)
 
 	p.nextToken()
 	p.nextToken()
@@ -104,25 +139,49 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 }
 
 func (p *Parser) peekTokenIsType() bool {
-	return p.peekToken.Type == token.KW_INTEGER || p.peekToken.Type == token.KW_REAL
+	return p.peekToken.Type == token.KW_INTEGER ||
+		p.peekToken.Type == token.KW_REAL ||
+		p.peekToken.Type == token.KW_STRING ||
+		p.peekToken.Type == token.KW_CHAR ||
+		p.peekToken.Type == token.KW_FILE
+}
+
+// peekTokenIsTypeName also accepts an identifier, which names a record type.
+func (p *Parser) peekTokenIsTypeName() bool {
+	return p.peekTokenIsType() || p.peekTokenIs(token.LEX_IDENT)
 }
 
 func (p *Parser) peekTokenIsVector() bool {
 	return p.peekToken.Type == token.KW_VECTOR
 }
 
+// DeclareType makes a record type declared in an earlier parse known, so
+// that `p: name;` is read as a declaration rather than a labeled statement.
+func (p *Parser) DeclareType(name string) {
+	p.types[name] = true
+}
+
 func (p *Parser) Errors() []string {
 	return p.errors
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be %s, got %s instead",
-		t, p.peekToken.Type)
+	msg := fmt.Sprintf("line %d, column %d: expected next token to be %s, got %s instead",
+		p.peekToken.Line, p.peekToken.Column, t, p.peekToken.Type)
+	p.errors = append(p.errors, msg)
+}
+
+// curError reports that the construct being parsed needed t where the
+// current token is, as when a block ends without its closing keyword.
+func (p *Parser) curError(t token.TokenType) {
+	msg := fmt.Sprintf("line %d, column %d: expected %s, got %s instead",
+		p.curToken.Line, p.curToken.Column, t, p.curToken.Type)
 	p.errors = append(p.errors, msg)
 }
 
-func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
+func (p *Parser) noPrefixParseFnError(tok token.Token) {
+	msg := fmt.Sprintf("line %d, column %d: no prefix parse function for %s found",
+		tok.Line, tok.Column, tok.Type)
 	p.errors = append(p.errors, msg)
 }
 
@@ -162,12 +221,110 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	}
 
 	if !p.curTokenIs(token.KW_END) {
+		p.curError(token.KW_END)
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseCaseExpression() ast.Expression {
+	expression := &ast.CaseExpression{Token: p.curToken}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.KW_OF) {
+		return nil
+	}
+	p.nextToken()
+
+	for !p.curTokenIs(token.KW_ELSE) && !p.curTokenIs(token.KW_END) && !p.curTokenIs(token.LEX_EOF) {
+		arm := p.parseCaseArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+		p.nextToken()
+	}
+
+	if p.curTokenIs(token.KW_ELSE) {
+		p.nextToken()
+		expression.Alternative = p.parseBlockStatement()
+	}
+
+	if !p.curTokenIs(token.KW_END) {
+		p.curError(token.KW_END)
 		return nil
 	}
 
 	return expression
 }
 
+// parseCaseArm parses `label {, label}: statement;` and leaves curToken on
+// the semicolon.
+func (p *Parser) parseCaseArm() *ast.CaseArm {
+	arm := &ast.CaseArm{}
+
+	for {
+		label := p.parseCaseLabel()
+		if label == nil {
+			return nil
+		}
+		arm.Labels = append(arm.Labels, label)
+
+		if !p.peekTokenIs(token.LEX_COMMA) {
+			break
+		}
+		p.nextToken()
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.LEX_COLON) {
+		return nil
+	}
+	p.nextToken()
+
+	arm.Body = &ast.BlockStatement{Token: p.curToken, Statements: []ast.Statement{}}
+	if p.curTokenIs(token.LEX_SEMICOLON) {
+		arm.Body.Statements = append(arm.Body.Statements, &ast.EmptyStatement{Token: p.curToken})
+		return arm
+	}
+
+	stmt := p.parseStatement()
+	if stmt == nil || !p.curTokenIs(token.LEX_SEMICOLON) {
+		return nil
+	}
+	arm.Body.Statements = append(arm.Body.Statements, stmt)
+
+	return arm
+}
+
+// parseCaseLabel parses an integer literal or a named constant, optionally
+// negated.
+func (p *Parser) parseCaseLabel() ast.Expression {
+	switch {
+	case p.curTokenIs(token.LEX_INT):
+		return p.parseIntegerLiteral()
+
+	case p.curTokenIs(token.LEX_IDENT):
+		return p.parseIdentifier()
+
+	case p.curTokenIs(token.LEX_MIN) && (p.peekTokenIs(token.LEX_INT) || p.peekTokenIs(token.LEX_IDENT)):
+		expression := &ast.PrefixExpression{Token: p.curToken, Operator: p.curToken.Literal}
+		p.nextToken()
+		expression.Right = p.parseCaseLabel()
+		if expression.Right == nil {
+			return nil
+		}
+		return expression
+	}
+
+	msg := fmt.Sprintf("expected case label, got %s instead", p.curToken.Type)
+	p.errors = append(p.errors, msg)
+	return nil
+}
+
 func (p *Parser) parseLoopExpression() ast.Expression {
 	expresison := &ast.LoopExpression{Token: p.curToken}
 
@@ -178,6 +335,86 @@ func (p *Parser) parseLoopExpression() ast.Expression {
 	return expresison
 }
 
+func (p *Parser) parseWhileExpression() ast.Expression {
+	expression := &ast.WhileExpression{Token: p.curToken}
+
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.KW_DO) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Body = p.parseBlockStatement()
+
+	if !p.curTokenIs(token.KW_END) {
+		p.curError(token.KW_END)
+		return nil
+	}
+
+	return expression
+}
+
+func (p *Parser) parseRepeatExpression() ast.Expression {
+	expression := &ast.RepeatExpression{Token: p.curToken}
+
+	p.nextToken()
+	expression.Body = p.parseBlockStatement()
+
+	if !p.curTokenIs(token.KW_UNTIL) {
+		p.curError(token.KW_UNTIL)
+		return nil
+	}
+
+	p.nextToken()
+	expression.Condition = p.parseExpression(LOWEST)
+
+	return expression
+}
+
+func (p *Parser) parseForExpression() ast.Expression {
+	expression := &ast.ForExpression{Token: p.curToken}
+
+	if !p.expectPeek(token.LEX_IDENT) {
+		return nil
+	}
+
+	expression.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LEX_ASSIGN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.From = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.KW_DOWNTO) {
+		expression.Downto = true
+	} else if !p.peekTokenIs(token.KW_TO) {
+		p.peekError(token.KW_TO)
+		return nil
+	}
+
+	p.nextToken()
+	p.nextToken()
+	expression.To = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.KW_DO) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Body = p.parseBlockStatement()
+
+	if !p.curTokenIs(token.KW_END) {
+		p.curError(token.KW_END)
+		return nil
+	}
+
+	return expression
+}
+
 func (p *Parser) parseBeginExpression() ast.Expression {
 	expression := &ast.BeginExpression{Token: p.curToken}
 
@@ -186,6 +423,7 @@ func (p *Parser) parseBeginExpression() ast.Expression {
 	expression.Block = p.parseBlockStatement()
 
 	if !p.curTokenIs(token.KW_END) {
+		p.curError(token.KW_END)
 		return nil
 	}
 
@@ -201,7 +439,8 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	}
 	block.Statements = []ast.Statement{}
 
-	for !p.curTokenIs(token.KW_ELSE) && !p.curTokenIs(token.KW_END) {
+	for !p.curTokenIs(token.KW_ELSE) && !p.curTokenIs(token.KW_END) &&
+		!p.curTokenIs(token.KW_UNTIL) && !p.curTokenIs(token.LEX_EOF) {
 		stmt := p.parseStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
@@ -241,26 +480,168 @@ func (p *Parser) parseStatement() ast.Statement {
 			return p.parseAssignStatement()
 		}
 
+		if p.peekTokenIs(token.LEX_COMMA) {
+			return p.parseDeclGroupStatement()
+		}
+
+		if p.peekTokenIs(token.LEX_LPAREN) {
+			return p.parseCallStatement()
+		}
+
+		if p.peekTokenIs(token.LEX_LBRACKET) || p.peekTokenIs(token.LEX_DOT) {
+			return p.parseElementStatement()
+		}
+
 		if !p.peekTokenIs(token.LEX_COLON) {
 			return p.parseExpressionStatement()
 		}
 
 		curTok := p.curToken
 		p.nextToken()
-		if p.peekTokenIsType() {
+		// `p: point;` declares a record variable, `l: x;` is labeled
+		if p.peekTokenIsType() || p.peekTokenIs(token.LEX_IDENT) && p.types[p.peekToken.Literal] {
 			return p.parseDeclStatement(curTok)
 		} else if p.peekTokenIsVector() {
 			return p.parseVectorStatement(curTok)
 		} else {
-			return p.parseMarkerStatement(curTok)
+			return p.parseLabeledStatement(curTok)
 		}
 	case token.KW_GOTO:
 		return p.parseGotoStatement()
+	case token.KW_EXIT:
+		return p.parseExitStatement()
+	case token.KW_ASSERT:
+		return p.parseAssertStatement()
+	case token.KW_PROCEDURE:
+		return p.parseProcedureStatement()
+	case token.KW_FUNCTION:
+		return p.parseFunctionStatement()
+	case token.KW_RETURN:
+		return p.parseReturnStatement()
+	case token.KW_CONST:
+		return p.parseConstStatement()
+	case token.KW_TYPE:
+		return p.parseTypeStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
+func (p *Parser) parseConstStatement() ast.Statement {
+	stmt := &ast.ConstStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LEX_IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LEX_EQ) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseTypeStatement() ast.Statement {
+	stmt := &ast.TypeStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LEX_IDENT) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.types[stmt.Name.Value] = true
+
+	if !p.expectPeek(token.LEX_EQ) {
+		return nil
+	}
+
+	if !p.expectPeek(token.KW_RECORD) {
+		return nil
+	}
+
+	stmt.Record = p.parseRecordType()
+	if stmt.Record == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseRecordType parses the fields of `record x, y: real; name: string end`.
+// The semicolon before end may be left out.
+func (p *Parser) parseRecordType() *ast.RecordType {
+	record := &ast.RecordType{Token: p.curToken}
+
+	for !p.peekTokenIs(token.KW_END) {
+		if !p.expectPeek(token.LEX_IDENT) {
+			return nil
+		}
+		names := []token.Token{p.curToken}
+
+		for p.peekTokenIs(token.LEX_COMMA) {
+			p.nextToken()
+			if !p.expectPeek(token.LEX_IDENT) {
+				return nil
+			}
+			names = append(names, p.curToken)
+		}
+
+		if !p.expectPeek(token.LEX_COLON) {
+			return nil
+		}
+
+		if p.peekTokenIsVector() {
+			decl := p.parseVectorType(names[0])
+			if decl == nil {
+				return nil
+			}
+			for _, name := range names {
+				d := *decl
+				d.Name = &ast.Identifier{Token: name, Value: name.Literal}
+				record.Fields = append(record.Fields, &d)
+			}
+		} else if p.peekTokenIsTypeName() {
+			decl := &ast.DeclStatment{Token: p.curToken}
+			p.nextToken()
+			decl.Type = &ast.Type{Token: p.curToken, Value: p.curToken.Literal}
+			for _, name := range names {
+				d := *decl
+				d.Name = &ast.Identifier{Token: name, Value: name.Literal}
+				record.Fields = append(record.Fields, &d)
+			}
+		} else {
+			msg := fmt.Sprintf("expected field type, got %s instead", p.peekToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		if !p.peekTokenIs(token.LEX_SEMICOLON) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.KW_END) {
+		return nil
+	}
+
+	return record
+}
+
 func (p *Parser) parseGotoStatement() *ast.GotoStatement {
 	stmt := &ast.GotoStatement{Token: p.curToken}
 
@@ -278,12 +659,435 @@ func (p *Parser) parseGotoStatement() *ast.GotoStatement {
 	return stmt
 }
 
+func (p *Parser) parseExitStatement() *ast.ExitStatement {
+	stmt := &ast.ExitStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.KW_WHEN) {
+		p.nextToken()
+		p.nextToken()
+		stmt.Condition = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseAssertStatement() ast.Statement {
+	stmt := &ast.AssertStatement{Token: p.curToken}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+	if stmt.Condition == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseProcedureStatement() ast.Statement {
+	stmt := &ast.ProcedureStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LEX_IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	stmt.Parameters = []*ast.Parameter{}
+
+	if p.peekTokenIs(token.LEX_LPAREN) {
+		p.nextToken()
+		stmt.Parameters = p.parseParameters()
+		if stmt.Parameters == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	if !p.expectPeek(token.KW_BEGIN) {
+		return nil
+	}
+
+	body, ok := p.parseBeginExpression().(*ast.BeginExpression)
+	if !ok {
+		return nil
+	}
+	stmt.Body = body
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseFunctionStatement() ast.Statement {
+	stmt := &ast.FunctionStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LEX_IDENT) {
+		return nil
+	}
+
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	stmt.Parameters = []*ast.Parameter{}
+
+	if p.peekTokenIs(token.LEX_LPAREN) {
+		p.nextToken()
+		stmt.Parameters = p.parseParameters()
+		if stmt.Parameters == nil {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.LEX_COLON) {
+		return nil
+	}
+
+	if !p.peekTokenIsType() {
+		msg := fmt.Sprintf("expected result type, got %s instead", p.peekToken.Type)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+	p.nextToken()
+	stmt.ReturnType = &ast.Type{Token: p.curToken, Value: p.curToken.Literal}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	if !p.expectPeek(token.KW_BEGIN) {
+		return nil
+	}
+
+	body, ok := p.parseBeginExpression().(*ast.BeginExpression)
+	if !ok {
+		return nil
+	}
+	stmt.Body = body
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseReturnStatement() ast.Statement {
+	stmt := &ast.ReturnStatement{Token: p.curToken}
+
+	if !p.peekTokenIs(token.LEX_SEMICOLON) {
+		p.nextToken()
+		stmt.ReturnValue = p.parseExpression(LOWEST)
+	}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseParameters parses `(a, b: integer; var c: real)` starting at the
+// opening parenthesis.
+func (p *Parser) parseParameters() []*ast.Parameter {
+	params := []*ast.Parameter{}
+
+	if p.peekTokenIs(token.LEX_RPAREN) {
+		p.nextToken()
+		return params
+	}
+
+	for {
+		p.nextToken()
+
+		byRef := false
+		if p.curTokenIs(token.KW_VAR) {
+			byRef = true
+			p.nextToken()
+		}
+
+		if !p.curTokenIs(token.LEX_IDENT) {
+			msg := fmt.Sprintf("expected parameter name, got %s instead", p.curToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+
+		names := []token.Token{p.curToken}
+		for p.peekTokenIs(token.LEX_COMMA) {
+			p.nextToken()
+			if !p.expectPeek(token.LEX_IDENT) {
+				return nil
+			}
+			names = append(names, p.curToken)
+		}
+
+		if !p.expectPeek(token.LEX_COLON) {
+			return nil
+		}
+
+		if !p.peekTokenIsTypeName() {
+			msg := fmt.Sprintf("expected parameter type, got %s instead", p.peekToken.Type)
+			p.errors = append(p.errors, msg)
+			return nil
+		}
+		p.nextToken()
+
+		typ := &ast.Type{Token: p.curToken, Value: p.curToken.Literal}
+		for _, name := range names {
+			params = append(params, &ast.Parameter{
+				Name:  &ast.Identifier{Token: name, Value: name.Literal},
+				Type:  typ,
+				ByRef: byRef,
+			})
+		}
+
+		if p.peekTokenIs(token.LEX_SEMICOLON) {
+			p.nextToken()
+			continue
+		}
+
+		if !p.expectPeek(token.LEX_RPAREN) {
+			return nil
+		}
+
+		return params
+	}
+}
+
+// parseCallStatement parses a statement that starts with name(. A bare call
+// becomes a call statement; anything else, such as f(x) + 1, stays an
+// expression statement.
+func (p *Parser) parseCallStatement() ast.Statement {
+	stmt := p.parseExpressionStatement()
+	if stmt == nil {
+		return nil
+	}
+
+	call, ok := stmt.Expression.(*ast.CallExpression)
+	if !ok {
+		return stmt
+	}
+
+	name, ok := call.Function.(*ast.Identifier)
+	if !ok {
+		return stmt
+	}
+
+	return &ast.CallStatement{Token: name.Token, Name: name, Arguments: call.Arguments}
+}
+
+// parseElementStatement parses a statement that starts with name[ or
+// name.: an assignment to a vector element or record field, or an
+// expression statement.
+func (p *Parser) parseElementStatement() ast.Statement {
+	name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	target := p.parseExpression(LOWEST)
+	if target == nil {
+		return nil
+	}
+
+	if !p.peekTokenIs(token.LEX_ASSIGN) {
+		if !p.expectPeek(token.LEX_SEMICOLON) {
+			return nil
+		}
+		return &ast.ExpressionStatement{Token: name.Token, Expression: target}
+	}
+	p.nextToken()
+
+	stmt := &ast.AssignStatement{Token: p.curToken, Name: name, Target: target}
+
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	for {
+		p.nextToken()
+
+		index := p.parseExpression(LOWEST)
+		if index == nil {
+			return nil
+		}
+		exp.Indices = append(exp.Indices, index)
+
+		if !p.peekTokenIs(token.LEX_COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectPeek(token.LEX_RBRACKET) {
+		return nil
+	}
+
+	return exp
+}
+
+func (p *Parser) parseFieldExpression(left ast.Expression) ast.Expression {
+	exp := &ast.FieldExpression{Token: p.curToken, Left: left}
+
+	if !p.expectPeek(token.LEX_IDENT) {
+		return nil
+	}
+	exp.Field = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
+func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
+	exp := &ast.CallExpression{Token: p.curToken, Function: function}
+	exp.Arguments = p.parseCallArguments()
+	if exp.Arguments == nil {
+		return nil
+	}
+	return exp
+}
+
+// parseCallArguments parses a parenthesized argument list starting at the
+// opening parenthesis.
+func (p *Parser) parseCallArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if p.peekTokenIs(token.LEX_RPAREN) {
+		p.nextToken()
+		return args
+	}
+
+	p.nextToken()
+	args = append(args, p.parseExpression(LOWEST))
+
+	for p.peekTokenIs(token.LEX_COMMA) {
+		p.nextToken()
+		p.nextToken()
+		args = append(args, p.parseExpression(LOWEST))
+	}
+
+	if !p.expectPeek(token.LEX_RPAREN) {
+		return nil
+	}
+
+	return args
+}
+
 func (p *Parser) parseReadExpression() ast.Expression {
 	exp := &ast.ReadExpression{Token: p.curToken}
 	exp.Arguments = p.parseExpressionList()
 	return exp
 }
 
+func (p *Parser) parseWriteExpression() ast.Expression {
+	exp := &ast.WriteExpression{Token: p.curToken}
+
+	for {
+		p.nextToken()
+
+		switch p.curToken.Type {
+		case token.KW_SKIP, token.KW_SPACE, token.KW_TAB:
+			exp.Arguments = append(exp.Arguments, &ast.WriteSpecifier{Token: p.curToken})
+		default:
+			arg := p.parseExpression(LOWEST)
+			if arg == nil {
+				return nil
+			}
+			if p.peekTokenIs(token.LEX_COLON) {
+				arg = p.parseWriteItem(arg)
+				if arg == nil {
+					return nil
+				}
+			}
+			exp.Arguments = append(exp.Arguments, arg)
+		}
+
+		if !p.peekTokenIs(token.LEX_COMMA) {
+			return exp
+		}
+		p.nextToken()
+	}
+}
+
+// writeBases are the suffixes that select the base of written integers,
+// the same ones that mark integer literals.
+var writeBases = map[string]bool{"B": true, "C": true, "H": true}
+
+// parseWriteItem parses the format suffixes after a write argument: a
+// width, then a precision or a base. A base ends the item.
+func (p *Parser) parseWriteItem(value ast.Expression) ast.Expression {
+	item := &ast.WriteItem{Token: p.peekToken, Value: value}
+
+	for p.peekTokenIs(token.LEX_COLON) && item.Precision == nil {
+		p.nextToken()
+		p.nextToken()
+
+		if p.curTokenIs(token.LEX_IDENT) && writeBases[p.curToken.Literal] {
+			item.Base = p.curToken.Literal
+			return item
+		}
+
+		suffix := p.parseExpression(LOWEST)
+		if suffix == nil {
+			return nil
+		}
+		if item.Width == nil {
+			item.Width = suffix
+		} else {
+			item.Precision = suffix
+		}
+	}
+
+	return item
+}
+
+func (p *Parser) parseStringLiteral() ast.Expression {
+	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+// parseConversion parses the type name in real(x) as the name of the
+// builtin function that converts to it.
+func (p *Parser) parseConversion() ast.Expression {
+	if !p.peekTokenIs(token.LEX_LPAREN) {
+		p.peekError(token.LEX_LPAREN)
+		return nil
+	}
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+}
+
+func (p *Parser) parseCharLiteral() ast.Expression {
+	value, _ := utf8.DecodeRuneInString(p.curToken.Literal)
+	return &ast.CharLiteral{Token: p.curToken, Value: value}
+}
+
+// parseIllegal reports a token the lexer could not read. A malformed
+// character literal arrives whole, quotes included, so it is named as such.
+func (p *Parser) parseIllegal() ast.Expression {
+	if !strings.HasPrefix(p.curToken.Literal, "'") {
+		p.noPrefixParseFnError(p.curToken)
+		return nil
+	}
+
+	msg := fmt.Sprintf("line %d, column %d: invalid character literal %s",
+		p.curToken.Line, p.curToken.Column, p.curToken.Literal)
+	p.errors = append(p.errors, msg)
+	return nil
+}
+
 func (p *Parser) parseExpressionList() []ast.Expression {
 	list := []ast.Expression{}
 
@@ -313,6 +1117,21 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 }
 
 func (p *Parser) parseVectorStatement(t token.Token) *ast.DeclStatmentVector {
+	stmt := p.parseVectorType(t)
+	if stmt == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.LEX_SEMICOLON) {
+		return nil
+	}
+
+	return stmt
+}
+
+// parseVectorType parses `vector[n, m] of type` after the colon of a
+// declaration, leaving the terminator to the caller.
+func (p *Parser) parseVectorType(t token.Token) *ast.DeclStatmentVector {
 	p.nextToken()
 	stmt := &ast.DeclStatmentVector{
 		Token: p.curToken,
@@ -322,41 +1141,122 @@ func (p *Parser) parseVectorStatement(t token.Token) *ast.DeclStatmentVector {
 		},
 	}
 
-	if !p.expectPeek(token.LEX_LBRACKET) {
+	// vector[n] of vector[m] of T is the same as vector[n, m] of T
+	for {
+		if !p.expectPeek(token.LEX_LBRACKET) {
+			return nil
+		}
+
+		for {
+			dim := p.parseVectorSize()
+			if dim == nil {
+				return nil
+			}
+			stmt.Dims = append(stmt.Dims, dim)
+
+			if !p.peekTokenIs(token.LEX_COMMA) {
+				break
+			}
+			p.nextToken()
+		}
+
+		if !p.expectPeek(token.LEX_RBRACKET) {
+			return nil
+		}
+
+		if !p.expectPeek(token.KW_OF) {
+			return nil
+		}
+
+		if !p.peekTokenIsVector() {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.peekTokenIsTypeName() {
+		msg := fmt.Sprintf("expected element type, got %s instead", p.peekToken.Type)
+		p.errors = append(p.errors, msg)
 		return nil
 	}
+	p.nextToken()
+
+	stmt.Type = &ast.Type{
+		Token: p.curToken,
+		Value: p.curToken.Literal,
+	}
 
+	return stmt
+}
+
+// parseVectorSize parses one dimension of a vector declaration: an integer
+// literal or a named constant.
+func (p *Parser) parseVectorSize() ast.Expression {
 	p.nextToken()
-	value, err := strconv.ParseUint(p.curToken.Literal, 0, 64)
 
-	if err != nil {
+	if p.curTokenIs(token.LEX_IDENT) {
+		return p.parseIdentifier()
+	}
+
+	value, ok := parseIntegerText(p.curToken.Literal)
+	if !p.curTokenIs(token.LEX_INT) || !ok || value.Sign() < 0 {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
 		p.errors = append(p.errors, msg)
 		return nil
 	}
 
-	stmt.Size = value
+	return p.parseIntegerLiteral()
+}
 
-	if !p.expectPeek(token.LEX_RBRACKET) {
-		return nil
+// parseDeclGroupStatement parses `a, b, c: type;`, giving every name its
+// own declaration of the shared type.
+func (p *Parser) parseDeclGroupStatement() ast.Statement {
+	names := []token.Token{p.curToken}
+
+	for p.peekTokenIs(token.LEX_COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.LEX_IDENT) {
+			return nil
+		}
+		names = append(names, p.curToken)
 	}
 
-	if !p.expectPeek(token.KW_OF) {
+	if !p.expectPeek(token.LEX_COLON) {
 		return nil
 	}
 
-	p.nextToken()
+	group := &ast.DeclGroupStatement{Token: p.curToken}
 
-	stmt.Type = &ast.Type{
-		Token: p.curToken,
-		Value: p.curToken.Literal,
-	}
+	if p.peekTokenIsTypeName() {
+		decl := p.parseDeclStatement(names[0])
+		if decl == nil {
+			return nil
+		}
 
-	if !p.expectPeek(token.LEX_SEMICOLON) {
+		for _, name := range names {
+			d := *decl
+			d.Name = &ast.Identifier{Token: name, Value: name.Literal}
+			group.Decls = append(group.Decls, &d)
+		}
+	} else if p.peekTokenIsVector() {
+		decl := p.parseVectorStatement(names[0])
+		if decl == nil {
+			return nil
+		}
+
+		for _, name := range names {
+			d := *decl
+			d.Name = &ast.Identifier{Token: name, Value: name.Literal}
+			group.Decls = append(group.Decls, &d)
+		}
+	} else {
+		msg := fmt.Sprintf("expected type after declaration list, got %s instead",
+			p.peekToken.Type)
+		p.errors = append(p.errors, msg)
 		return nil
 	}
 
-	return stmt
+	return group
 }
 
 func (p *Parser) parseDeclStatement(t token.Token) *ast.DeclStatment {
@@ -399,9 +1299,25 @@ func (p *Parser) parseAssignStatement() *ast.AssignStatement {
 	return stmt
 }
 
-func (p *Parser) parseMarkerStatement(t token.Token) *ast.MarkerStatement {
-	stmt := &ast.MarkerStatement{Token: p.curToken}
-	stmt.Marker = &ast.Identifier{Token: t, Value: t.Literal}
+func (p *Parser) parseLabeledStatement(t token.Token) ast.Statement {
+	stmt := &ast.LabeledStatement{Token: p.curToken}
+	stmt.Label = &ast.Identifier{Token: t, Value: t.Literal}
+
+	switch p.peekToken.Type {
+	case token.KW_END, token.KW_ELSE, token.KW_UNTIL, token.LEX_EOF:
+		stmt.Statement = &ast.EmptyStatement{Token: p.peekToken}
+		return stmt
+	case token.LEX_SEMICOLON:
+		p.nextToken()
+		stmt.Statement = &ast.EmptyStatement{Token: p.curToken}
+		return stmt
+	}
+
+	p.nextToken()
+	stmt.Statement = p.parseStatement()
+	if stmt.Statement == nil {
+		return nil
+	}
 
 	return stmt
 }
@@ -410,7 +1326,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParseFns[p.curToken.Type]
 
 	if prefix == nil {
-		p.noPrefixParseFnError(p.curToken.Type)
+		p.noPrefixParseFnError(p.curToken)
 		return nil
 	}
 
@@ -443,18 +1359,56 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	value, ok := parseIntegerText(p.curToken.Literal)
 
-	if err != nil {
+	if !ok {
 		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
 		p.errors = append(p.errors, msg)
 		return nil
 	}
 
+	if value.IsInt64() {
+		lit.Value = value.Int64()
+	} else {
+		lit.Big = value
+	}
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as real", p.curToken.Literal)
+		p.errors = append(p.errors, msg)
+		return nil
+	}
+
 	lit.Value = value
 	return lit
 }
 
+// parseIntegerText reads an integer in one of the grammar's forms: binary
+// with a B suffix, octal with C, hexadecimal with H and decimal with an
+// optional D.
+func parseIntegerText(literal string) (*big.Int, bool) {
+	digits, base := literal, 10
+
+	switch literal[len(literal)-1] {
+	case 'B', 'b':
+		digits, base = literal[:len(literal)-1], 2
+	case 'C', 'c':
+		digits, base = literal[:len(literal)-1], 8
+	case 'D', 'd':
+		digits, base = literal[:len(literal)-1], 10
+	case 'H', 'h':
+		digits, base = literal[:len(literal)-1], 16
+	}
+
+	return new(big.Int).SetString(digits, base)
+}
+
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
 	p.prefixParseFns[tokenType] = fn
 }
@@ -468,9 +1422,14 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 		Token:    p.curToken,
 		Operator: p.curToken.Literal,
 	}
+	precedence := PREFIX
+	if p.curTokenIs(token.KW_NOT) {
+		precedence = NOT
+	}
+
 	p.nextToken()
 
-	expression.Right = p.parseExpression(PREFIX)
+	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
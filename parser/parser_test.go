@@ -50,6 +50,69 @@ func TestDeclStatments(t *testing.T) {
 	}
 }
 
+func TestDeclGroupStatements(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedNames []string
+		expectedType  string
+	}{
+		{"a, b, c: integer;", []string{"a", "b", "c"}, "integer"},
+		{"x, y: real;", []string{"x", "y"}, "real"},
+		{"s, t: string;", []string{"s", "t"}, "string"},
+		{"u, v: vector[3] of real;", []string{"u", "v"}, "real"},
+		{"p, q: point;", []string{"p", "q"}, "point"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement. got=%d",
+				len(program.Statements))
+		}
+
+		group, ok := program.Statements[0].(*ast.DeclGroupStatement)
+		if !ok {
+			t.Fatalf("s not *ast.DeclGroupStatement. got=%T", program.Statements[0])
+		}
+
+		if len(group.Decls) != len(tt.expectedNames) {
+			t.Fatalf("group.Decls does not contain %d declarations. got=%d",
+				len(tt.expectedNames), len(group.Decls))
+		}
+
+		for i, name := range tt.expectedNames {
+			switch decl := group.Decls[i].(type) {
+			case *ast.DeclStatment:
+				testIdentifier(t, decl.Name, name)
+				testTypeExpression(t, decl.Type, tt.expectedType)
+			case *ast.DeclStatmentVector:
+				testIdentifier(t, decl.Name, name)
+				testTypeExpression(t, decl.Type, tt.expectedType)
+			default:
+				t.Errorf("group.Decls[%d] is not a declaration. got=%T", i, decl)
+			}
+		}
+
+		if program.String() != tt.input+"\n" {
+			t.Errorf("program.String() wrong. want=%q, got=%q",
+				tt.input+"\n", program.String())
+		}
+	}
+
+	for _, input := range []string{"a, b;", "a, 5: integer;", "a, b: 5;"} {
+		l := lexer.New(input)
+		p := New(l)
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
 func testIdentifier(t *testing.T, s *ast.Identifier, name string) bool {
 
 	if s.Value != name {
@@ -179,6 +242,145 @@ func testIntegerLiteral(t *testing.T, il ast.Expression, value int64) bool {
 	return true
 }
 
+func TestIntegerLiteralForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"42;", 42},
+		{"42D;", 42},
+		{"42d;", 42},
+		{"010;", 10},
+		{"101B;", 5},
+		{"101b;", 5},
+		{"17C;", 15},
+		{"17c;", 15},
+		{"0FFH;", 255},
+		{"5ABh;", 1451},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal %s has wrong value. got=%d, want=%d",
+				tt.input, literal.Value, tt.expected)
+		}
+	}
+
+	l := lexer.New("1343456B;")
+	p := New(l)
+	p.ParseProgram()
+	if len(p.Errors()) != 1 {
+		t.Errorf("expected an error for an invalid binary literal. got=%v", p.Errors())
+	}
+
+	l = lexer.New("123456789012345678901234567890;")
+	p = New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	literal := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+	if literal.Big == nil || literal.Big.String() != "123456789012345678901234567890" {
+		t.Errorf("literal.Big wrong. got=%v", literal.Big)
+	}
+}
+
+func TestFloatLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{"5.;", 5},
+		{".25;", 0.25},
+		{"1.5E+10;", 1.5e10},
+		{"2e-3;", 0.002},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+}
+
+func TestCharLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected rune
+		str      string
+	}{
+		{"'a';", 'a', "'a'"},
+		{"'\\n';", '\n', "'\\n'"},
+		{"'\\'';", '\'', "'\\''"},
+		{"'é';", 'é', "'é'"},
+		{"'''';", '\'', "'\\''"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.CharLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.CharLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %q. got=%q", tt.expected, literal.Value)
+		}
+		if literal.String() != tt.str {
+			t.Errorf("literal.String() not %q. got=%q", tt.str, literal.String())
+		}
+	}
+
+	p := New(lexer.New("c: char;"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if program.String() != "c: char;\n" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"c := 'ab';", "line 1, column 6: invalid character literal 'ab'"},
+		{"c := '';", "line 1, column 6: invalid character literal ''"},
+		{"c := '\\q';", "line 1, column 6: invalid character literal '\\q'"},
+		{"c := 'a\n;", "line 1, column 6: invalid character literal 'a"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) != 1 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. got=%v, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -344,6 +546,58 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"-(5 + 5);",
 			"(-(5 + 5))",
 		},
+		{
+			"a <= b = c >= d;",
+			"((a <= b) = (c >= d))",
+		},
+		{
+			"a < b and c > d;",
+			"((a < b) and (c > d))",
+		},
+		{
+			"a or b and c;",
+			"(a or (b and c))",
+		},
+		{
+			"a and b or c and d;",
+			"((a and b) or (c and d))",
+		},
+		{
+			"not a = b and c;",
+			"((not (a = b)) and c)",
+		},
+		{
+			"not not a or b;",
+			"((not (not a)) or b)",
+		},
+		{
+			"-a < 0 or not (b + 1);",
+			"(((-a) < 0) or (not (b + 1)))",
+		},
+		{
+			"a + f(b * c) * d;",
+			"(a + (f((b * c)) * d))",
+		},
+		{
+			"f(a, g(b + 1), -c) - 1;",
+			"(f(a, g((b + 1)), (-c)) - 1)",
+		},
+		{
+			"-f(a);",
+			"(-f(a))",
+		},
+		{
+			`s + "!" = t;`,
+			`((s + "!") = t)`,
+		},
+		{
+			"real(n) / 2;",
+			"(real(n) / 2)",
+		},
+		{
+			"a + b div c * d;",
+			"(a + ((b div c) * d))",
+		},
 	}
 
 	for _, tt := range tests {
@@ -402,6 +656,105 @@ func TestReadExpression(t *testing.T) {
 
 }
 
+func TestWriteExpression(t *testing.T) {
+	input := `write "sum = ", a + 1, skip, space, tab, "a\tb";`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.WriteExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.WriteExpression. got=%T",
+			stmt.Expression)
+	}
+
+	if len(exp.Arguments) != 6 {
+		t.Fatalf("wrong number of arguments. got=%d", len(exp.Arguments))
+	}
+
+	str, ok := exp.Arguments[0].(*ast.StringLiteral)
+	if !ok {
+		t.Fatalf("exp.Arguments[0] is not ast.StringLiteral. got=%T", exp.Arguments[0])
+	}
+	if str.Value != "sum = " {
+		t.Errorf("str.Value not %q. got=%q", "sum = ", str.Value)
+	}
+
+	testInfixExpression(t, exp.Arguments[1], "a", "+", 1)
+
+	for i, spec := range []string{"skip", "space", "tab"} {
+		ws, ok := exp.Arguments[i+2].(*ast.WriteSpecifier)
+		if !ok {
+			t.Fatalf("exp.Arguments[%d] is not ast.WriteSpecifier. got=%T",
+				i+2, exp.Arguments[i+2])
+		}
+		if ws.TokenLiteral() != spec {
+			t.Errorf("specifier %d not %q. got=%q", i, spec, ws.TokenLiteral())
+		}
+	}
+
+	expected := `write "sum = ", (a + 1), skip, space, tab, "a\tb";`
+	if exp.String() != expected {
+		t.Errorf("exp.String() wrong. want=%q, got=%q", expected, exp.String())
+	}
+}
+
+func TestWriteItems(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		width     bool
+		precision bool
+		base      string
+	}{
+		{"write x:8;", "x:8", true, false, ""},
+		{"write x:n + 1:2;", "x:(n + 1):2", true, true, ""},
+		{"write n:H;", "n:H", false, false, "H"},
+		{"write n:12:B;", "n:12:B", true, false, "B"},
+		{"write -n:4:C;", "(-n):4:C", true, false, "C"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		exp := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.WriteExpression)
+		item, ok := exp.Arguments[0].(*ast.WriteItem)
+		if !ok {
+			t.Fatalf("argument of %q is not ast.WriteItem. got=%T", tt.input, exp.Arguments[0])
+		}
+		if item.String() != tt.expected {
+			t.Errorf("item.String() wrong. want=%q, got=%q", tt.expected, item.String())
+		}
+		if (item.Width != nil) != tt.width || (item.Precision != nil) != tt.precision || item.Base != tt.base {
+			t.Errorf("wrong suffixes for %q. got width=%v, precision=%v, base=%q",
+				tt.input, item.Width, item.Precision, item.Base)
+		}
+	}
+
+	l := lexer.New("write x:8:2:H;")
+	p := New(l)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected parser errors for a base after a precision")
+	}
+}
+
 func TestIfExpression(t *testing.T) {
 	input := `if x < y then x := y; end;`
 
@@ -529,6 +882,88 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestCaseExpression(t *testing.T) {
+	input := `case x + 1 of
+		1, 10B: y := 1;
+		-3: begin y := 2; z := 3; end;
+		0FH: ;
+	else
+		y := 0;
+		z := 0;
+	end;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.CaseExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.CaseExpression. got=%T",
+			stmt.Expression)
+	}
+
+	testInfixExpression(t, exp.Subject, "x", "+", 1)
+
+	expectedLabels := [][]string{{"1", "10B"}, {"(-3)"}, {"0FH"}}
+	if len(exp.Arms) != len(expectedLabels) {
+		t.Fatalf("wrong number of arms. want=%d, got=%d", len(expectedLabels), len(exp.Arms))
+	}
+
+	for i, labels := range expectedLabels {
+		arm := exp.Arms[i]
+		if len(arm.Labels) != len(labels) {
+			t.Fatalf("arm %d has wrong number of labels. want=%d, got=%d",
+				i, len(labels), len(arm.Labels))
+		}
+		for j, label := range labels {
+			if arm.Labels[j].String() != label {
+				t.Errorf("arm %d label %d wrong. want=%q, got=%q",
+					i, j, label, arm.Labels[j].String())
+			}
+		}
+		if len(arm.Body.Statements) != 1 {
+			t.Errorf("arm %d body does not contain 1 statement. got=%d",
+				i, len(arm.Body.Statements))
+		}
+	}
+
+	if _, ok := exp.Arms[2].Body.Statements[0].(*ast.EmptyStatement); !ok {
+		t.Errorf("arm 2 body is not ast.EmptyStatement. got=%T", exp.Arms[2].Body.Statements[0])
+	}
+
+	if exp.Alternative == nil || len(exp.Alternative.Statements) != 2 {
+		t.Fatalf("else branch does not contain 2 statements. got=%+v", exp.Alternative)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{`case x of "y": z := 1; end;`, "expected case label, got STR instead"},
+		{"case x of 1 z := 1; end;", "line 1, column 13: expected next token to be :, got IDENT instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. got=%v, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
 func TestBeginExpression(t *testing.T) {
 	input := `begin 
 							x := y;
@@ -601,12 +1036,8 @@ func TestGoto(t *testing.T) {
 	}
 }
 
-func TestLoop(t *testing.T) {
-	input := `loop begin
-							count := 2;
-							goto foo;
-							co: integer;
-						end;`
+func TestAssertStatement(t *testing.T) {
+	input := `assert n > 0 and s = "ok";`
 
 	l := lexer.New(input)
 	p := New(l)
@@ -618,33 +1049,95 @@ func TestLoop(t *testing.T) {
 			1, len(program.Statements))
 	}
 
-	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	stmt, ok := program.Statements[0].(*ast.AssertStatement)
 	if !ok {
-		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+		t.Fatalf("program.Statements[0] is not ast.AssertStatement. got=%T",
 			program.Statements[0])
 	}
 
-	loop, ok := (stmt.Expression).(*ast.LoopExpression)
-	if !ok {
-		t.Fatalf("program.Statements[0].Expression is not ast.LoopExpression. got=%T",
-			stmt.Expression)
+	expected := "assert ((n > 0) and (s = \"ok\"))\n"
+	if stmt.String() != expected {
+		t.Errorf("stmt.String() wrong. want=%q, got=%q", expected, stmt.String())
 	}
 
-	beg, ok := loop.Body.(*ast.BeginExpression)
-	if len(beg.Block.Statements) != 3 {
-		t.Fatalf("BeginExpression.Block.Statements does not contain %d statements. got=%d\n",
-			1, len(program.Statements))
+	p = New(lexer.New("assert;"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected parser errors for assert without a condition")
 	}
+}
 
-	_, ok = beg.Block.Statements[0].(*ast.AssignStatement)
-	if !ok {
-		t.Fatalf("ass[0] is not ast.AssignStatement. got=%T",
-			beg.Block.Statements[0])
+func TestMalformedBlocks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"repeat write 1; end; write 3;", "line 1, column 17: expected UNTIL, got END instead"},
+		{"repeat write 1;", "line 1, column 16: expected UNTIL, got EOF instead"},
+		{"while x > 0 write 1; end;", "line 1, column 13: expected next token to be DO, got WRITE instead"},
+		{"while x > 0 do write 1;", "line 1, column 24: expected END, got EOF instead"},
+		{"for i := 1 2 do end;", "line 1, column 12: expected next token to be TO, got INT instead"},
+		{"for i := 1 downto 0 write i; end;", "line 1, column 21: expected next token to be DO, got WRITE instead"},
+		{"for i := 1 to 3 do write i;", "line 1, column 28: expected END, got EOF instead"},
+		{"case x 1: write 1; end;", "line 1, column 8: expected next token to be OF, got INT instead"},
+		{"case x of 1: write 1;", "line 1, column 22: expected END, got EOF instead"},
+		{"if x then write 1;", "line 1, column 19: expected END, got EOF instead"},
+		{"begin write 1;", "line 1, column 15: expected END, got EOF instead"},
 	}
 
-	_, ok = beg.Block.Statements[1].(*ast.GotoStatement)
-	if !ok {
-		t.Fatalf("ass[1] is not ast.GotoStatement. got=%T",
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. got=%v, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
+func TestLoop(t *testing.T) {
+	input := `loop begin
+							count := 2;
+							goto foo;
+							co: integer;
+						end;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	loop, ok := (stmt.Expression).(*ast.LoopExpression)
+	if !ok {
+		t.Fatalf("program.Statements[0].Expression is not ast.LoopExpression. got=%T",
+			stmt.Expression)
+	}
+
+	beg, ok := loop.Body.(*ast.BeginExpression)
+	if len(beg.Block.Statements) != 3 {
+		t.Fatalf("BeginExpression.Block.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	_, ok = beg.Block.Statements[0].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("ass[0] is not ast.AssignStatement. got=%T",
+			beg.Block.Statements[0])
+	}
+
+	_, ok = beg.Block.Statements[1].(*ast.GotoStatement)
+	if !ok {
+		t.Fatalf("ass[1] is not ast.GotoStatement. got=%T",
 			beg.Block.Statements[1])
 	}
 
@@ -656,6 +1149,93 @@ func TestLoop(t *testing.T) {
 
 }
 
+func TestLoopForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"while x < 10 do x := x + 1; end;",
+			"while (x < 10) do\nx := (x + 1);\nend\n",
+		},
+		{
+			"repeat x := x + 1; until x > 10;",
+			"repeat\nx := (x + 1);\nuntil (x > 10)",
+		},
+		{
+			"for i := 1 to n do s := s + i; end;",
+			"for i := 1 to n do\ns := (s + i);\nend\n",
+		},
+		{
+			"for i := n * 2 downto 0 do end;",
+			"for i := (n * 2) downto 0 do\nend\n",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		actual := program.String()
+		if actual != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, actual)
+		}
+	}
+
+	l := lexer.New("for i := 1 do end;")
+	p := New(l)
+	p.ParseProgram()
+	if len(p.Errors()) == 0 {
+		t.Errorf("expected an error for a for loop without to/downto")
+	}
+}
+
+func TestExitStatement(t *testing.T) {
+	tests := []struct {
+		input     string
+		condition string
+	}{
+		{"exit;", ""},
+		{"exit when a > 5;", "(a > 5)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ExitStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExitStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if tt.condition == "" {
+			if stmt.Condition != nil {
+				t.Errorf("stmt.Condition is not nil. got=%s", stmt.Condition)
+			}
+			continue
+		}
+
+		if stmt.Condition == nil || stmt.Condition.String() != tt.condition {
+			t.Errorf("stmt.Condition wrong. want=%s, got=%v", tt.condition, stmt.Condition)
+		}
+	}
+}
+
 func TestVector(t *testing.T) {
 	input := `v: vector[15] of integer;`
 
@@ -679,9 +1259,12 @@ func TestVector(t *testing.T) {
 		return
 	}
 
-	if stmt.Size != 15 {
-		t.Fatalf("stmt size is not 15. got=%d",
-			stmt.Size)
+	if len(stmt.Dims) != 1 {
+		t.Fatalf("stmt does not have 1 dimension. got=%d", len(stmt.Dims))
+	}
+
+	if !testIntegerLiteral(t, stmt.Dims[0], 15) {
+		return
 	}
 
 	if stmt.TokenLiteral() != "vector" {
@@ -694,6 +1277,203 @@ func TestVector(t *testing.T) {
 
 }
 
+func TestMatrix(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"m: vector[3, 4] of real;", "m: vector[3, 4] of real;\n"},
+		{"m: vector[3] of vector[n] of integer;", "m: vector[3, n] of integer;\n"},
+		{"m[i, 2] := m[2, i] + 1;", "m[i, 2] := (m[2, i] + 1);\n"},
+		{"v[i];", "v[i]"},
+		{"read m[1, j];", "read m[1, j];"},
+		{"x := -v[1] * f(v[2]);", "x := ((-v[1]) * f(v[2]));\n"},
+		{"v[i + 1] := v[n - i, f(j)];", "v[(i + 1)] := v[(n - i), f(j)];\n"},
+		{"v[w[i]];", "v[w[i]]"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program for %q. want=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	p := New(lexer.New("m[i, j] := 0;"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.AssignStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.AssignStatement")
+	}
+	if !testIdentifier(t, stmt.Name, "m") {
+		return
+	}
+	index, ok := stmt.Target.(*ast.IndexExpression)
+	if !ok || len(index.Indices) != 2 {
+		t.Fatalf("stmt.Target is not a two-index ast.IndexExpression. got=%#v", stmt.Target)
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"v[i +] := 0;", "line 1, column 6: no prefix parse function for ] found"},
+		{"m: vector[3, ] of real;", "could not parse \"]\" as integer"},
+		{"m: vector[3] of;", "expected element type, got ; instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. got=%v, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	input := `type student = record name: string; grades: vector[5] of integer; at: point end;`
+
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt, ok := program.Statements[0].(*ast.TypeStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.TypeStatement. got=%T",
+			program.Statements[0])
+	}
+	if !testIdentifier(t, stmt.Name, "student") {
+		return
+	}
+	if len(stmt.Record.Fields) != 3 {
+		t.Fatalf("record does not have 3 fields. got=%d", len(stmt.Record.Fields))
+	}
+	if _, ok := stmt.Record.Fields[1].(*ast.DeclStatmentVector); !ok {
+		t.Errorf("field grades is not ast.DeclStatmentVector. got=%T", stmt.Record.Fields[1])
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"type point = record x, y: real end;", "type point = record x: real; y: real; end;\n"},
+		{"type empty = record end;", "type empty = record end;\n"},
+		{"type p = record x: real; end;", "type p = record x: real; end;\n"},
+		{"type point = record end; p: point;", "type point = record end;\np: point;\n"},
+		{"l: x;", "l:\nx"},
+		{"pts: vector[3] of point;", "pts: vector[3] of point;\n"},
+		{"p.x := q.y * 2.0;", "p.x := (q.y * 2.0);\n"},
+		{"s.grades[i] := pts[i].x;", "s.grades[i] := pts[i].x;\n"},
+		{"a.b.c;", "a.b.c"},
+		{"read p.x;", "read p.x;"},
+		{"procedure move(var p: point; d: real); begin end;", "procedure move(var p: point; d: real);\nbegin\nend\n"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("wrong program for %q. want=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+
+	p = New(lexer.New("q: point;"))
+	p.DeclareType("point")
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	if _, ok := program.Statements[0].(*ast.DeclStatment); !ok {
+		t.Errorf("declaration of a known type is not ast.DeclStatment. got=%T", program.Statements[0])
+	}
+
+	errorTests := []struct {
+		input    string
+		expected string
+	}{
+		{"type point = record x, y: real;", "line 1, column 32: expected next token to be IDENT, got EOF instead"},
+		{"type point = record x: 5 end;", "expected field type, got INT instead"},
+		{"type point = real;", "line 1, column 14: expected next token to be RECORD, got REAL instead"},
+		{"p. := 1;", "line 1, column 4: expected next token to be IDENT, got := instead"},
+	}
+
+	for _, tt := range errorTests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong parser errors for %q. got=%v, want=%q", tt.input, p.Errors(), tt.expected)
+		}
+	}
+}
+
+func TestConstStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedName  string
+		expectedValue string
+	}{
+		{"const n = 10;", "n", "10"},
+		{"const mask = 0FFH;", "mask", "0FFH"},
+		{"const m = n * 2 + 1;", "m", "((n * 2) + 1)"},
+		{"const pi = 3.14159;", "pi", "3.14159"},
+		{`const title = "results";`, "title", `"results"`},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ConstStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ConstStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if !testIdentifier(t, stmt.Name, tt.expectedName) {
+			return
+		}
+
+		if stmt.Value.String() != tt.expectedValue {
+			t.Errorf("stmt.Value wrong. want=%q, got=%q", tt.expectedValue, stmt.Value.String())
+		}
+	}
+
+	input := "const n = 4; v: vector[n] of real; case x of n, -n: ; end;"
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	vector, ok := program.Statements[1].(*ast.DeclStatmentVector)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not ast.DeclStatmentVector. got=%T",
+			program.Statements[1])
+	}
+	if !testIdentifier(t, vector.Dims[0].(*ast.Identifier), "n") {
+		return
+	}
+	if vector.String() != "v: vector[n] of real;\n" {
+		t.Errorf("vector.String() wrong. got=%q", vector.String())
+	}
+
+	caseExp := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.CaseExpression)
+	if caseExp.Arms[0].Labels[0].String() != "n" || caseExp.Arms[0].Labels[1].String() != "(-n)" {
+		t.Errorf("case labels wrong. got=%v", caseExp.Arms[0].Labels)
+	}
+}
+
 func TestParser(t *testing.T) {
 	input := `fasf:
 	count: integer;
@@ -715,41 +1495,45 @@ func TestParser(t *testing.T) {
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
 
-	if len(program.Statements) != 5 {
+	if len(program.Statements) != 4 {
 		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
-			1, len(program.Statements))
+			4, len(program.Statements))
 	}
 
-	_, ok := program.Statements[0].(*ast.MarkerStatement)
+	labeled, ok := program.Statements[0].(*ast.LabeledStatement)
 	if !ok {
-		t.Fatalf("program.Statements[0] is not ast.MarkerStatement. got=%T",
+		t.Fatalf("program.Statements[0] is not ast.LabeledStatement. got=%T",
 			program.Statements[0])
 	}
 
+	if !testIdentifier(t, labeled.Label, "fasf") {
+		return
+	}
+
+	_, ok = labeled.Statement.(*ast.DeclStatment)
+	if !ok {
+		t.Fatalf("labeled.Statement is not ast.DeclStatment. got=%T",
+			labeled.Statement)
+	}
+
 	_, ok = program.Statements[1].(*ast.DeclStatment)
 	if !ok {
 		t.Fatalf("program.Statements[1] is not ast.DeclStatment. got=%T",
 			program.Statements[1])
 	}
 
-	_, ok = program.Statements[2].(*ast.DeclStatment)
+	exp_stmt, ok := program.Statements[2].(*ast.ExpressionStatement)
 	if !ok {
-		t.Fatalf("program.Statements[2] is not ast.DeclStatment. got=%T",
+		t.Fatalf("program.Statements[2] is not ast.ExpressionStatement. got=%T",
 			program.Statements[2])
 	}
 
-	exp_stmt, ok := program.Statements[3].(*ast.ExpressionStatement)
+	_, ok = program.Statements[3].(*ast.GotoStatement)
 	if !ok {
-		t.Fatalf("program.Statements[3] is not ast.ExpressionStatement. got=%T",
+		t.Fatalf("program.Statements[3] is not ast.GotoStatement. got=%T",
 			program.Statements[3])
 	}
 
-	_, ok = program.Statements[4].(*ast.GotoStatement)
-	if !ok {
-		t.Fatalf("program.Statements[4] is not ast.GotoStatement. got=%T",
-			program.Statements[4])
-	}
-
 	begin, ok := exp_stmt.Expression.(*ast.BeginExpression)
 	if !ok {
 		t.Fatalf("expstmt.Expression is not ast.BeginExpression. got=%T",
@@ -808,6 +1592,257 @@ func TestParser(t *testing.T) {
 	}
 }
 
+func TestProcedureStatement(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedName   string
+		expectedParams []string
+		expectedBody   int
+	}{
+		{"procedure p; begin end;", "p", []string{}, 0},
+		{"procedure p(); begin x := 1; end;", "p", []string{}, 1},
+		{
+			"procedure swap(var a, b: integer); begin t: integer; t := a; a := b; b := t; end;",
+			"swap", []string{"var a: integer", "var b: integer"}, 4,
+		},
+		{
+			"procedure show(n: integer; var r: real; x, y: real); begin end;",
+			"show", []string{"n: integer", "var r: real", "x: real", "y: real"}, 0,
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ProcedureStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ProcedureStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if !testIdentifier(t, stmt.Name, tt.expectedName) {
+			return
+		}
+
+		if len(stmt.Parameters) != len(tt.expectedParams) {
+			t.Fatalf("wrong number of parameters. want=%d, got=%d",
+				len(tt.expectedParams), len(stmt.Parameters))
+		}
+
+		for i, param := range tt.expectedParams {
+			if stmt.Parameters[i].String() != param {
+				t.Errorf("parameter %d wrong. want=%q, got=%q",
+					i, param, stmt.Parameters[i].String())
+			}
+		}
+
+		if len(stmt.Body.Block.Statements) != tt.expectedBody {
+			t.Errorf("body does not contain %d statements. got=%d",
+				tt.expectedBody, len(stmt.Body.Block.Statements))
+		}
+	}
+}
+
+func TestFunctionStatement(t *testing.T) {
+	input := `function max(a, b: integer): integer;
+	begin
+		if a > b then
+			return a;
+		end;
+		return b;
+	end;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.FunctionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FunctionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testIdentifier(t, stmt.Name, "max") {
+		return
+	}
+
+	if len(stmt.Parameters) != 2 {
+		t.Fatalf("wrong number of parameters. got=%d", len(stmt.Parameters))
+	}
+
+	if stmt.ReturnType.Value != "integer" {
+		t.Errorf("stmt.ReturnType.Value not %q. got=%q", "integer", stmt.ReturnType.Value)
+	}
+
+	if len(stmt.Body.Block.Statements) != 2 {
+		t.Fatalf("body does not contain 2 statements. got=%d",
+			len(stmt.Body.Block.Statements))
+	}
+
+	ret, ok := stmt.Body.Block.Statements[1].(*ast.ReturnStatement)
+	if !ok {
+		t.Fatalf("body.Statements[1] is not ast.ReturnStatement. got=%T",
+			stmt.Body.Block.Statements[1])
+	}
+
+	testLiteralExpression(t, ret.ReturnValue, "b")
+}
+
+func TestReturnStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"return;", "return;\n"},
+		{"return x;", "return x;\n"},
+		{"return f(x - 1) * x;", "return (f((x - 1)) * x);\n"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ReturnStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ReturnStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. want=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestCallStatement(t *testing.T) {
+	input := `swap(a, b + 1); reset();`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+			2, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.CallStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.CallStatement. got=%T",
+			program.Statements[0])
+	}
+
+	if !testIdentifier(t, stmt.Name, "swap") {
+		return
+	}
+
+	if len(stmt.Arguments) != 2 {
+		t.Fatalf("wrong number of arguments. got=%d", len(stmt.Arguments))
+	}
+
+	testLiteralExpression(t, stmt.Arguments[0], "a")
+	testInfixExpression(t, stmt.Arguments[1], "b", "+", 1)
+
+	stmt, ok = program.Statements[1].(*ast.CallStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not ast.CallStatement. got=%T",
+			program.Statements[1])
+	}
+
+	if len(stmt.Arguments) != 0 {
+		t.Errorf("wrong number of arguments. got=%d", len(stmt.Arguments))
+	}
+}
+
+func TestLabeledStatement(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedLabel string
+		expectedStmt  string
+	}{
+		{"done: x := 1;", "done", "*ast.AssignStatement"},
+		{"again: goto again;", "again", "*ast.GotoStatement"},
+		{"top: loop begin end;", "top", "*ast.ExpressionStatement"},
+		{"fin: ;", "fin", "*ast.EmptyStatement"},
+		{"fin:", "fin", "*ast.EmptyStatement"},
+		{"outer: inner: x := 1;", "outer", "*ast.LabeledStatement"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Body does not contain %d statements. got=%d\n",
+				1, len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.LabeledStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.LabeledStatement. got=%T",
+				program.Statements[0])
+		}
+
+		if !testIdentifier(t, stmt.Label, tt.expectedLabel) {
+			return
+		}
+
+		if fmt.Sprintf("%T", stmt.Statement) != tt.expectedStmt {
+			t.Errorf("stmt.Statement is not %s. got=%T", tt.expectedStmt, stmt.Statement)
+		}
+	}
+
+	input := `begin
+		x := 1;
+		fin:
+	end;`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	begin := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.BeginExpression)
+	if len(begin.Block.Statements) != 2 {
+		t.Fatalf("begin.Block.Statements does not contain 2 statements. got=%d",
+			len(begin.Block.Statements))
+	}
+
+	labeled, ok := begin.Block.Statements[1].(*ast.LabeledStatement)
+	if !ok {
+		t.Fatalf("begin.Block.Statements[1] is not ast.LabeledStatement. got=%T",
+			begin.Block.Statements[1])
+	}
+
+	if _, ok := labeled.Statement.(*ast.EmptyStatement); !ok {
+		t.Errorf("labeled.Statement is not ast.EmptyStatement. got=%T", labeled.Statement)
+	}
+}
+
 func testInfixExpression(t *testing.T, exp ast.Expression, left interface{},
 	operator string, right interface{}) bool {
 
@@ -4,36 +4,35 @@ import (
 	"bufio"
 	"fmt"
 	"interp/evaluator"
-	"interp/lexer"
-	"interp/object"
-	"interp/parser"
+	"interp/interpreter"
 	"io"
 )
 
 const PROMPT = ">> "
 
-func Start(in io.Reader, out io.Writer) {
+// Start reads and runs lines until the input ends or a program halts, and
+// returns the exit code passed to halt, or zero.
+func Start(in io.Reader, out io.Writer, interp *interpreter.Interpreter) int {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
 
 	for {
 		fmt.Printf(PROMPT)
 		scanned := scanner.Scan()
 		if !scanned {
-			return
+			return 0
 		}
 
-		line := scanner.Text()
-		l := lexer.New(line)
-		p := parser.New(l)
-
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		result := interp.Run(scanner.Text())
+		if len(result.Errors) != 0 {
+			printErrors(out, result.Errors)
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
+		if result.Halted {
+			return result.ExitCode
+		}
+
+		evaluated := result.Value
 		if evaluated != evaluator.NULL {
 			io.WriteString(out, evaluated.Inspect())
 			io.WriteString(out, "\n")
@@ -41,8 +40,8 @@ func Start(in io.Reader, out io.Writer) {
 	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
-	io.WriteString(out, " parser errors:\n")
+func printErrors(out io.Writer, errors []string) {
+	io.WriteString(out, " errors:\n")
 	for _, msg := range errors {
 		io.WriteString(out, "\t"+msg+"\n")
 	}
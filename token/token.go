@@ -7,6 +7,8 @@ const (
 	LEX_IDENT = "IDENT" // add, foobar, x, y, ...
 	LEX_INT   = "INT"   // 1343456B, 1343456b, 1343456C, 1343456c, 1343456D, 1343456H
 	LEX_FLOAT = "FLOAT" // 3.14159, 1.5E+10, .25E-5
+	LEX_STR   = "STR"   // "sum = ", "a\tb"
+	LEX_CHR   = "CHR"   // 'a', '\n'
 
 	// Operators
 	LEX_ASSIGN = ":="
@@ -25,6 +27,7 @@ const (
 	LEX_COMMA     = ","
 	LEX_SEMICOLON = ";"
 	LEX_COLON     = ":"
+	LEX_DOT       = "."
 
 	LEX_LPAREN   = "("
 	LEX_RPAREN   = ")"
@@ -32,43 +35,93 @@ const (
 	LEX_RBRACKET = "]"
 
 	// Keywords
-	KW_GOTO    = "GOTO"
-	KW_INTEGER = "INTEGER"
-	KW_REAL    = "REAL"
-	KW_READ    = "READ"
-	KW_WRITE   = "WRITE"
-	KW_IF      = "IF"
-	KW_ELSE    = "ELSE"
-	KW_THEN    = "THEN"
-	KW_END     = "END"
-	KW_LOOP    = "LOOP"
-	KW_BEGIN   = "BEGIN"
-	KW_SKIP    = "SKIP"
-	KW_SPACE   = "SPACE"
-	KW_TAB     = "TAB"
-	KW_MOD     = "MOD"
-	KW_OF      = "OF"
-	KW_VECTOR  = "VECTOR"
+	KW_GOTO      = "GOTO"
+	KW_INTEGER   = "INTEGER"
+	KW_REAL      = "REAL"
+	KW_READ      = "READ"
+	KW_WRITE     = "WRITE"
+	KW_IF        = "IF"
+	KW_ELSE      = "ELSE"
+	KW_THEN      = "THEN"
+	KW_END       = "END"
+	KW_LOOP      = "LOOP"
+	KW_BEGIN     = "BEGIN"
+	KW_SKIP      = "SKIP"
+	KW_SPACE     = "SPACE"
+	KW_TAB       = "TAB"
+	KW_MOD       = "MOD"
+	KW_DIV       = "DIV"
+	KW_OF        = "OF"
+	KW_VECTOR    = "VECTOR"
+	KW_EXIT      = "EXIT"
+	KW_WHEN      = "WHEN"
+	KW_WHILE     = "WHILE"
+	KW_DO        = "DO"
+	KW_REPEAT    = "REPEAT"
+	KW_UNTIL     = "UNTIL"
+	KW_FOR       = "FOR"
+	KW_TO        = "TO"
+	KW_DOWNTO    = "DOWNTO"
+	KW_AND       = "AND"
+	KW_OR        = "OR"
+	KW_NOT       = "NOT"
+	KW_PROCEDURE = "PROCEDURE"
+	KW_VAR       = "VAR"
+	KW_FUNCTION  = "FUNCTION"
+	KW_RETURN    = "RETURN"
+	KW_STRING    = "STRING"
+	KW_CASE      = "CASE"
+	KW_CONST     = "CONST"
+	KW_TYPE      = "TYPE"
+	KW_RECORD    = "RECORD"
+	KW_CHAR      = "CHAR"
+	KW_FILE      = "FILE"
+	KW_ASSERT    = "ASSERT"
 )
 
 var keywords = map[string]TokenType{
-	"integer": KW_INTEGER,
-	"real":    KW_REAL,
-	"read":    KW_READ,
-	"goto":    KW_GOTO,
-	"if":      KW_IF,
-	"else":    KW_ELSE,
-	"write":   KW_WRITE,
-	"then":    KW_THEN,
-	"end":     KW_END,
-	"loop":    KW_LOOP,
-	"begin":   KW_BEGIN,
-	"skip":    KW_SKIP,
-	"tab":     KW_TAB,
-	"space":   KW_SPACE,
-	"mod":     KW_MOD,
-	"of":      KW_OF,
-	"vector":  KW_VECTOR,
+	"integer":   KW_INTEGER,
+	"real":      KW_REAL,
+	"read":      KW_READ,
+	"goto":      KW_GOTO,
+	"if":        KW_IF,
+	"else":      KW_ELSE,
+	"write":     KW_WRITE,
+	"then":      KW_THEN,
+	"end":       KW_END,
+	"loop":      KW_LOOP,
+	"begin":     KW_BEGIN,
+	"skip":      KW_SKIP,
+	"tab":       KW_TAB,
+	"space":     KW_SPACE,
+	"mod":       KW_MOD,
+	"div":       KW_DIV,
+	"of":        KW_OF,
+	"vector":    KW_VECTOR,
+	"exit":      KW_EXIT,
+	"when":      KW_WHEN,
+	"while":     KW_WHILE,
+	"do":        KW_DO,
+	"repeat":    KW_REPEAT,
+	"until":     KW_UNTIL,
+	"for":       KW_FOR,
+	"to":        KW_TO,
+	"downto":    KW_DOWNTO,
+	"and":       KW_AND,
+	"or":        KW_OR,
+	"not":       KW_NOT,
+	"procedure": KW_PROCEDURE,
+	"var":       KW_VAR,
+	"function":  KW_FUNCTION,
+	"return":    KW_RETURN,
+	"string":    KW_STRING,
+	"case":      KW_CASE,
+	"const":     KW_CONST,
+	"type":      KW_TYPE,
+	"record":    KW_RECORD,
+	"char":      KW_CHAR,
+	"file":      KW_FILE,
+	"assert":    KW_ASSERT,
 }
 
 func LookUpIdent(ident string) TokenType {
@@ -83,4 +136,6 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int // 1-based position of the first character
+	Column  int
 }